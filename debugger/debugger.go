@@ -2,6 +2,7 @@ package debugger
 
 import (
 	"fmt"
+	"go-boy/disasm"
 	"go-boy/emulator"
 	"go-boy/internal"
 	"slices"
@@ -20,6 +21,9 @@ type Debugger struct {
 	doFastStep  bool
 	breakpoints []uint16
 	lastBPHit   int
+	breakOnLock bool
+
+	Symbols disasm.Symbols // label names for the disassembly, can be nil
 
 	window *glfw.Window
 
@@ -41,6 +45,7 @@ func (d *Debugger) reset() {
 	d.doStep = false
 	d.lastBPHit = -1
 	d.breakpoints = nil
+	d.breakOnLock = true
 }
 
 func (d *Debugger) GetBreakpoints() []uint16 {
@@ -82,15 +87,18 @@ func (d *Debugger) RunEmulator() {
 			d.e.Window.SetKeyCallback(d.debugKeyCallback)
 		}
 
-		d.e.SerialOut()
 		if d.autorun {
 			if slices.Contains(d.GetBreakpoints(), d.e.Cpu.PC) && d.e.Cpu.PC != uint16(d.lastBPHit) {
 				d.autorun = false
 				d.lastBPHit = int(d.e.Cpu.PC)
 			} else {
 				d.lastBPHit = -1
+				wasLocked := d.e.Cpu.Locked
 				d.e.Step()
-
+				if d.breakOnLock && !wasLocked && d.e.Cpu.Locked {
+					d.autorun = false
+					d.e.DelegateDrawToDebugger = true
+				}
 			}
 		} else {
 			if d.doStep {
@@ -109,6 +117,9 @@ func (d *Debugger) RunEmulator() {
 
 	}
 
+	if err := d.e.FlushSave(); err != nil {
+		println(err.Error())
+	}
 }
 
 func (d *Debugger) Render() {
@@ -181,9 +192,10 @@ func (d *Debugger) RenderDebugger() {
 	imgui.Text("Control: ")
 	imgui.SameLine()
 	imgui.Text("F3=Start  F4=Stop  F5=Step  F6=FastStep  F7=Restart")
+	imgui.Checkbox("Break when the cpu locks up", &d.breakOnLock)
 
 	imgui.Text("Regs: ")
-	if imgui.BeginTable("Regs", 13, imgui.TableFlags_None, imgui.Vec2{X: 0, Y: 0}, 0.0) {
+	if imgui.BeginTable("Regs", 14, imgui.TableFlags_None, imgui.Vec2{X: 0, Y: 0}, 0.0) {
 		imgui.TableSetupColumn(fmt.Sprintf("PC: 0x%04x", d.e.Cpu.PC), 0, 0, 0)
 		imgui.TableSetupColumn(fmt.Sprintf("SP: 0x%04x", d.e.Cpu.SP), 0, 0, 0)
 		imgui.TableSetupColumn(fmt.Sprintf("A: 0x%02x", d.e.Cpu.A), 0, 0, 0)
@@ -197,12 +209,18 @@ func (d *Debugger) RenderDebugger() {
 		imgui.TableSetupColumn(fmt.Sprintf("DIV: 0x%02x", d.e.Cpu.Memory.Io.GetDIV()), 0, 0, 0)
 		imgui.TableSetupColumn(fmt.Sprintf("TIMA: 0x%02x", d.e.Cpu.Memory.Io.GetTIMA()), 0, 0, 0)
 		imgui.TableSetupColumn(fmt.Sprintf("HALT: %t", d.e.Cpu.Halt), 0, 0, 0)
+		imgui.TableSetupColumn(fmt.Sprintf("CPU: %s", d.e.Cpu.Status()), 0, 0, 0)
 		imgui.TableHeadersRow()
 		imgui.EndTable()
 	}
+	imgui.Text(fmt.Sprintf("Next: %s", d.e.Cpu.NextInstruction()))
 
 	imgui.BeginChild("TabsRegion")
 	if imgui.BeginTabBar("Memory Regions") {
+		if imgui.BeginTabItem("Disasm") {
+			d.RenderDisassembly(64)
+			imgui.EndTabItem()
+		}
 		if imgui.BeginTabItem("Bank0") {
 			d.RenderMemoryTable("Bank0", d.e.Cpu.Memory.GetBank0(), 0, true)
 			imgui.EndTabItem()
@@ -326,6 +344,49 @@ func (d *Debugger) RenderMemoryTable(id string, slice []uint8, offset uint32, de
 	}
 }
 
+// RenderDisassembly shows count instructions starting at PC, clicking one toggles a breakpoint
+func (d *Debugger) RenderDisassembly(count int) {
+	if imgui.BeginTable("Disassembly", 3, imgui.TableFlags_None, imgui.Vec2{X: 0, Y: 0}, 0.0) {
+		addr := d.e.Cpu.PC
+		for i := 0; i < count; i++ {
+			inst := disasm.DecodeMmap(d.e.Cpu.Memory, addr)
+
+			if name, ok := d.Symbols.Lookup(inst.Address); ok {
+				imgui.TableNextRow(0, 0.0)
+				imgui.TableNextColumn()
+				imgui.TableNextColumn()
+				imgui.TableNextColumn()
+				imgui.Text(name + ":")
+			}
+
+			imgui.TableNextRow(0, 0.0)
+			imgui.TableNextColumn()
+			pushed := 0
+			if d.isCurrentPC(addr) {
+				imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{X: 0, Y: 1, Z: 0, W: 1})
+				pushed++
+			} else if d.isInDebug(addr) {
+				imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{X: 0, Y: 0.7, Z: 0.7, W: 1})
+				pushed++
+			}
+			imgui.Text(inst.Address.String())
+			imgui.TableNextColumn()
+			imgui.Text(inst.Hex())
+			imgui.TableNextColumn()
+			if imgui.Selectable(fmt.Sprintf("%s##dis%04x", inst.Text(d.Symbols), addr)) {
+				d.ToggleBP(addr)
+			}
+			for pushed > 0 {
+				imgui.PopStyleColor()
+				pushed--
+			}
+
+			addr += uint16(inst.Length())
+		}
+		imgui.EndTable()
+	}
+}
+
 func (d *Debugger) isCurrentPC(addr uint16) bool {
 	return d.e.Cpu.PC == addr
 }
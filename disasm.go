@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go-boy/disasm"
+	"go-boy/internal"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// go-boy disasm [flags] rom writes an RGBDS listing of the rom
+func runDisasm(args []string) int {
+	flags := flag.NewFlagSet("disasm", flag.ExitOnError)
+	output := flags.String("o", "", "file to write the listing to, stdout by default")
+	symPath := flags.String("sym", "", "RGBDS .sym file with label names, by default one next to the rom is used")
+	entry := flags.String("entry", "", "file to load from a rom archive, the first rom by default")
+
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %s disasm [flags] rom\n\n", os.Args[0])
+		fmt.Fprintln(flags.Output(), "Writes the rom as RGBDS assembly that builds back into the same rom:")
+		fmt.Fprintln(flags.Output(), "  rgbasm -o game.o game.asm && rgblink -o game.gb game.o")
+		fmt.Fprintln(flags.Output())
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	rom, err := internal.NewRomFromArchive(flags.Arg(0), *entry)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	var symbols disasm.Symbols
+	if *symPath == "" {
+		*symPath = defaultSymPath(rom)
+		if _, err := os.Stat(*symPath); err != nil {
+			*symPath = ""
+		}
+	}
+	if *symPath != "" {
+		if symbols, err = disasm.LoadSymbols(*symPath); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	listing := disasm.NewListing(rom.GetData(), symbols)
+	listing.Title = rom.Header.Title
+
+	out := os.Stdout
+	if *output != "" {
+		if out, err = os.Create(*output); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer out.Close()
+	}
+	if err := listing.WriteAsm(out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// game.gb comes with game.sym, roms from archives look for the symbols next to the archive
+func defaultSymPath(rom *internal.Rom) string {
+	path := rom.Path
+	if rom.Entry != "" {
+		path = filepath.Join(filepath.Dir(rom.Path), filepath.Base(filepath.FromSlash(rom.Entry)))
+	}
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".sym"
+}
@@ -0,0 +1,169 @@
+// Package disasm turns SM83 machine code into RGBDS assembly.
+// Instructions are decoded with the opcode table the cpu runs on (internal.OPCODES).
+package disasm
+
+import (
+	"fmt"
+	"go-boy/internal"
+	"strings"
+)
+
+// Address in the banked memory map, Bank is the rom bank for 0x0000-0x7FFF and 0 elsewhere
+type Address struct {
+	Bank int
+	Addr uint16
+}
+
+// Same notation as RGBDS .sym files
+func (a Address) String() string {
+	return fmt.Sprintf("%02X:%04X", a.Bank, a.Addr)
+}
+
+func (a Address) IsRom() bool {
+	return a.Addr < 0x8000
+}
+
+// Resolve returns the address target lands on when jumped to from a.
+// The fixed bank and everything above the rom stays put, the switchable bank is only known
+// if a is in it as well.
+func (a Address) Resolve(target uint16) (Address, bool) {
+	switch {
+	case target < 0x4000:
+		return Address{Bank: 0, Addr: target}, true
+	case target < 0x8000:
+		if a.Addr >= 0x4000 && a.Addr < 0x8000 {
+			return Address{Bank: a.Bank, Addr: target}, true
+		}
+		return Address{Addr: target}, false
+	}
+	return Address{Addr: target}, true
+}
+
+// A decoded instruction, Opcode is nil if the bytes ran out before its end
+type Instruction struct {
+	Address   Address
+	Opcode    *internal.Opcode
+	Bytes     []uint8
+	Immediate uint16
+}
+
+// Decode reads the instruction at the start of data, which is located at address
+func Decode(data []uint8, address Address) Instruction {
+	if len(data) == 0 {
+		return Instruction{Address: address}
+	}
+	read := func(addr uint16) uint8 {
+		offset := int(addr - address.Addr)
+		if offset >= len(data) {
+			return 0
+		}
+		return data[offset]
+	}
+	op := internal.Decode(read, address.Addr)
+	if int(op.Length) > len(data) {
+		return Instruction{Address: address, Bytes: data}
+	}
+	return Instruction{
+		Address:   address,
+		Opcode:    op,
+		Bytes:     data[:op.Length],
+		Immediate: op.Immediate(read, address.Addr),
+	}
+}
+
+// DecodeMmap reads the instruction at address from the live memory map
+func DecodeMmap(m *internal.Mmap, address uint16) Instruction {
+	data := make([]uint8, 3)
+	for i := range data {
+		data[i], _ = m.ReadByteAtForced(address + uint16(i))
+	}
+	return Decode(data, Address{Bank: m.RomBank(address), Addr: address})
+}
+
+func (i Instruction) Length() int {
+	if i.Opcode == nil {
+		return len(i.Bytes)
+	}
+	return int(i.Opcode.Length)
+}
+
+// Flow control, for the listing of a whole rom
+func (i Instruction) IsCall() bool {
+	return i.Opcode != nil && (strings.HasPrefix(i.Opcode.Mnemonic, "CALL") || strings.HasPrefix(i.Opcode.Mnemonic, "RST"))
+}
+
+func (i Instruction) IsJump() bool {
+	return i.Opcode != nil && (strings.HasPrefix(i.Opcode.Mnemonic, "JP") || strings.HasPrefix(i.Opcode.Mnemonic, "JR"))
+}
+
+// EndsFlow is true if the instruction after this one is never executed by falling through
+func (i Instruction) EndsFlow() bool {
+	if i.Opcode == nil || i.Opcode.Illegal {
+		return true
+	}
+	switch i.Opcode.Mnemonic {
+	case "JP a16", "JR e8", "JP HL", "RET", "RETI":
+		return true
+	}
+	return false
+}
+
+// Target returns the address a jump or call goes to, false for indirect jumps and other instructions
+func (i Instruction) Target() (uint16, bool) {
+	if !i.IsJump() && !i.IsCall() {
+		return 0, false
+	}
+	switch i.Opcode.Operand {
+	case internal.OPERAND_A16:
+		return i.Immediate, true
+	case internal.OPERAND_REL8:
+		return i.Opcode.Target(i.Immediate, i.Address.Addr), true
+	}
+	if strings.HasPrefix(i.Opcode.Mnemonic, "RST") {
+		return uint16(i.Bytes[0] & 0x38), true
+	}
+	return 0, false
+}
+
+// Text writes the instruction in RGBDS syntax, addresses with a name in symbols are replaced by it
+func (i Instruction) Text(symbols Symbols) string {
+	if i.Opcode == nil || i.Opcode.Illegal {
+		return i.Data()
+	}
+	op := i.Opcode
+	switch op.Operand {
+	case internal.OPERAND_A8:
+		if name, ok := symbols.Lookup(Address{Addr: 0xFF00 | i.Immediate}); ok {
+			return op.WithOperand(name)
+		}
+	case internal.OPERAND_A16, internal.OPERAND_REL8:
+		target := i.Immediate
+		if op.Operand == internal.OPERAND_REL8 {
+			target = op.Target(i.Immediate, i.Address.Addr)
+		}
+		if address, ok := i.Address.Resolve(target); ok {
+			if name, ok := symbols.Lookup(address); ok {
+				return op.WithOperand(name)
+			}
+		}
+	}
+	return op.Format(i.Immediate, i.Address.Addr)
+}
+
+// Data writes the bytes of the instruction as a db directive
+func (i Instruction) Data() string {
+	return dataDirective(i.Bytes)
+}
+
+func dataDirective(data []uint8) string {
+	values := make([]string, len(data))
+	for j, b := range data {
+		values[j] = fmt.Sprintf("$%02X", b)
+	}
+	return "db " + strings.Join(values, ", ")
+}
+
+// Hex dump of the bytes, for comments and the debugger
+func (i Instruction) Hex() string {
+	return fmt.Sprintf("% x", i.Bytes)
+}
@@ -0,0 +1,346 @@
+package disasm
+
+import (
+	"bufio"
+	"fmt"
+	"go-boy/internal"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+const bankSize = 0x4000
+
+// Where the boot rom hands over and where rst and interrupts go, unused vectors
+// are usually filled with $00 or $FF and are only disassembled if something jumps there
+var ENTRY_POINTS = []struct {
+	Addr      uint16
+	Name      string
+	Mandatory bool
+}{
+	{0x0100, "EntryPoint", true},
+	{0x0000, "RST_00", false},
+	{0x0008, "RST_08", false},
+	{0x0010, "RST_10", false},
+	{0x0018, "RST_18", false},
+	{0x0020, "RST_20", false},
+	{0x0028, "RST_28", false},
+	{0x0030, "RST_30", false},
+	{0x0038, "RST_38", false},
+	{0x0040, "VBlankInterrupt", false},
+	{0x0048, "StatInterrupt", false},
+	{0x0050, "TimerInterrupt", false},
+	{0x0058, "SerialInterrupt", false},
+	{0x0060, "JoypadInterrupt", false},
+}
+
+// The cartridge header after the entry point is never code
+const (
+	headerStart = 0x0104
+	headerEnd   = 0x0150
+)
+
+// Listing of a whole rom. Code is found by following jumps and calls from the entry points,
+// everything that is never reached is written out as data.
+type Listing struct {
+	Title string // written at the top of the listing
+
+	rom     []uint8
+	symbols Symbols
+
+	length  []int // instruction length at the offset of its first byte, -1 for the other bytes, 0 for data
+	labels  map[int]string
+	targets map[int]Address // resolved jump targets by offset of the instruction
+}
+
+// NewListing disassembles rom, symbols can be nil
+func NewListing(rom []uint8, symbols Symbols) *Listing {
+	l := &Listing{
+		rom:     rom,
+		symbols: symbols,
+		length:  make([]int, len(rom)),
+		labels:  map[int]string{},
+		targets: map[int]Address{},
+	}
+	for _, entry := range ENTRY_POINTS {
+		offset := int(entry.Addr)
+		if offset >= len(rom) || (!entry.Mandatory && (rom[offset] == 0x00 || rom[offset] == 0xFF)) {
+			continue
+		}
+		l.labels[offset] = entry.Name
+		l.trace(offset)
+	}
+	return l
+}
+
+func (l *Listing) banks() int {
+	return (len(l.rom) + bankSize - 1) / bankSize
+}
+
+func (l *Listing) address(offset int) Address {
+	bank := offset / bankSize
+	if bank == 0 {
+		return Address{Bank: 0, Addr: uint16(offset)}
+	}
+	return Address{Bank: bank, Addr: uint16(bankSize + offset%bankSize)}
+}
+
+func (l *Listing) offset(address Address) (int, bool) {
+	var offset int
+	switch {
+	case address.Addr < bankSize && address.Bank == 0:
+		offset = int(address.Addr)
+	case address.Addr >= bankSize && address.Addr < 2*bankSize && address.Bank > 0:
+		offset = address.Bank*bankSize + int(address.Addr-bankSize)
+	default:
+		return 0, false
+	}
+	return offset, offset < len(l.rom)
+}
+
+// IsCode tells if the byte at offset in the rom belongs to an instruction
+func (l *Listing) IsCode(offset int) bool {
+	return offset >= 0 && offset < len(l.rom) && l.length[offset] != 0
+}
+
+// trace marks the instructions reachable from offset as code
+func (l *Listing) trace(start int) {
+	queue := []int{start}
+	for len(queue) > 0 {
+		offset := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+
+		// bank switches of the form LD A, n8 / LD [$2000-$3FFF], A tell where
+		// jumps into 0x4000-0x7FFF end up
+		loadedA, switchedBank := -1, -1
+
+		for offset < len(l.rom) && l.length[offset] == 0 {
+			inst := l.decode(offset)
+			if inst.Opcode == nil || inst.Opcode.Illegal || !l.unclaimed(offset, inst.Length()) {
+				break
+			}
+			l.length[offset] = inst.Length()
+			for i := 1; i < inst.Length(); i++ {
+				l.length[offset+i] = -1
+			}
+
+			switch {
+			case inst.Opcode.Mnemonic == "LD A, n8":
+				loadedA = int(inst.Immediate)
+			case inst.Opcode.Mnemonic == "LD [a16], A" && inst.Immediate >= 0x2000 && inst.Immediate < 0x4000 && loadedA >= 0:
+				switchedBank = max(loadedA, 1)
+			default:
+				loadedA = -1
+			}
+
+			if target, ok := inst.Target(); ok {
+				address, known := inst.Address.Resolve(target)
+				if !known && switchedBank >= 0 {
+					address.Bank, known = switchedBank, true
+				} else if !known && l.banks() == 2 {
+					address.Bank, known = 1, true
+				}
+				if targetOffset, ok := l.offset(address); known && ok {
+					l.targets[offset] = address
+					if _, named := l.labels[targetOffset]; !named {
+						kind := "Jump"
+						if inst.IsCall() {
+							kind = "Call"
+						}
+						l.labels[targetOffset] = fmt.Sprintf("%s_%02X_%04X", kind, address.Bank, address.Addr)
+					}
+					queue = append(queue, targetOffset)
+				}
+			}
+
+			if inst.EndsFlow() {
+				break
+			}
+			offset += inst.Length()
+		}
+	}
+}
+
+// decode reads the instruction at offset, it has to end in the same bank and must not run into the header
+func (l *Listing) decode(offset int) Instruction {
+	end := min((offset/bankSize+1)*bankSize, len(l.rom))
+	if offset < headerStart {
+		end = min(end, headerStart)
+	} else if offset < headerEnd {
+		end = offset
+	}
+	return Decode(l.rom[offset:end], l.address(offset))
+}
+
+func (l *Listing) unclaimed(offset int, length int) bool {
+	for i := offset; i < offset+length; i++ {
+		if l.length[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// The names that end up in the listing: labels at instruction starts or in data
+// and symbols outside of the rom, which are written as constants
+func (l *Listing) definedSymbols() (Symbols, map[int]string) {
+	defined := Symbols{}
+	placed := map[int]string{}
+	for address, name := range l.symbols {
+		if !address.IsRom() {
+			if !strings.Contains(name, ".") {
+				defined[address] = name
+			}
+			continue
+		}
+		if offset, ok := l.offset(address); ok && l.length[offset] >= 0 {
+			placed[offset] = labelName(name)
+		}
+	}
+	for offset, name := range l.labels {
+		if _, ok := placed[offset]; !ok && l.length[offset] >= 0 {
+			placed[offset] = name
+		}
+	}
+	for offset, name := range placed {
+		defined[l.address(offset)] = name
+	}
+	return defined, placed
+}
+
+// Local labels like Parent.child can only be defined inside their parent, they are flattened
+func labelName(name string) string {
+	return strings.ReplaceAll(name, ".", "_")
+}
+
+// WriteAsm writes the listing in RGBDS syntax, it assembles back to the same rom with
+// rgbasm -o game.o game.asm && rgblink -o game.gb game.o
+func (l *Listing) WriteAsm(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	defined, placed := l.definedSymbols()
+
+	// header titles are not always clean ascii
+	title := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7E {
+			return -1
+		}
+		return r
+	}, l.Title)
+	if title != "" {
+		fmt.Fprintf(out, "; %s\n", title)
+	}
+	fmt.Fprintf(out, "; %d bytes, %d banks\n", len(l.rom), l.banks())
+
+	constants := slices.SortedFunc(maps.Keys(defined), compareAddress)
+	constants = slices.DeleteFunc(constants, func(a Address) bool { return a.IsRom() })
+	if len(constants) > 0 {
+		fmt.Fprintln(out)
+	}
+	written := map[string]bool{}
+	for _, address := range constants {
+		if name := defined[address]; !written[name] {
+			fmt.Fprintf(out, "DEF %s EQU $%04X\n", name, address.Addr)
+			written[name] = true
+		}
+	}
+
+	for offset := 0; offset < len(l.rom); {
+		if offset%bankSize == 0 {
+			bank := offset / bankSize
+			if bank == 0 {
+				fmt.Fprintf(out, "\nSECTION \"ROM Bank $%03X\", ROM0[$0000]\n", bank)
+			} else {
+				fmt.Fprintf(out, "\nSECTION \"ROM Bank $%03X\", ROMX[$4000], BANK[$%03X]\n", bank, bank)
+			}
+		}
+		if name, ok := placed[offset]; ok {
+			fmt.Fprintf(out, "\n%s:\n", name)
+		}
+
+		if l.length[offset] > 0 {
+			inst := l.decode(offset)
+			text, note := l.instructionText(inst, offset, defined)
+			writeLine(out, text, inst.Address, strings.TrimSpace(inst.Hex()+"  "+note))
+			offset += inst.Length()
+			continue
+		}
+
+		// data runs up to the next instruction, label or bank
+		end := offset + 1
+		for end < len(l.rom) && end%bankSize != 0 && l.length[end] == 0 && placed[end] == "" {
+			end++
+		}
+		l.writeData(out, offset, end)
+		offset = end
+	}
+	return out.Flush()
+}
+
+// instructionText returns the source of inst and a note for the comment
+func (l *Listing) instructionText(inst Instruction, offset int, defined Symbols) (string, string) {
+	// rgbasm can not be told which encoding to use for these, the bytes are kept as they are
+	switch {
+	case inst.Opcode.Mnemonic == "STOP" && inst.Bytes[1] != 0x00:
+		return inst.Data(), "STOP"
+	case inst.Opcode.Operand == internal.OPERAND_A16 && inst.Immediate >= 0xFF00 &&
+		(inst.Opcode.Mnemonic == "LD [a16], A" || inst.Opcode.Mnemonic == "LD A, [a16]"):
+		return inst.Data(), inst.Opcode.Format(inst.Immediate, inst.Address.Addr)
+	}
+	if target, ok := l.targets[offset]; ok {
+		if name, ok := defined[target]; ok {
+			return inst.Opcode.WithOperand(name), ""
+		}
+	}
+	return inst.Text(defined), ""
+}
+
+// writeData writes the bytes in [start, end), long runs of the same value as ds
+func (l *Listing) writeData(out io.Writer, start int, end int) {
+	for offset := start; offset < end; {
+		run := offset + 1
+		for run < end && l.rom[run] == l.rom[offset] {
+			run++
+		}
+		if run-offset >= 16 {
+			writeLine(out, fmt.Sprintf("ds %d, $%02X", run-offset, l.rom[offset]), l.address(offset), "")
+			offset = run
+			continue
+		}
+
+		lineEnd := min(offset+16, end)
+		// leave the next run for ds
+		for i := offset + 1; i < lineEnd; i++ {
+			if i+16 <= end && allEqual(l.rom[i:i+16]) {
+				lineEnd = i
+				break
+			}
+		}
+		writeLine(out, dataDirective(l.rom[offset:lineEnd]), l.address(offset), "")
+		offset = lineEnd
+	}
+}
+
+func allEqual(data []uint8) bool {
+	for _, b := range data {
+		if b != data[0] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeLine(out io.Writer, text string, address Address, hex string) {
+	comment := address.String()
+	if hex != "" {
+		comment += "  " + hex
+	}
+	fmt.Fprintf(out, "\t%-32s ; %s\n", text, comment)
+}
+
+func compareAddress(a, b Address) int {
+	if a.Bank != b.Bank {
+		return a.Bank - b.Bank
+	}
+	return int(a.Addr) - int(b.Addr)
+}
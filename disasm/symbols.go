@@ -0,0 +1,80 @@
+package disasm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var ErrSymbolLine = errors.New("invalid symbol line")
+
+// Label names by address, as written by rgblink -n
+type Symbols map[Address]string
+
+// LoadSymbols reads an RGBDS .sym file, lines look like "01:4000 Label" and comments start with ';'
+func LoadSymbols(path string) (Symbols, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening symbols %q: %w", path, err)
+	}
+	defer f.Close()
+
+	symbols := Symbols{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), ";")
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		address, err := parseSymbolAddress(fields[0])
+		if len(fields) != 2 || err != nil {
+			return nil, fmt.Errorf("%w: %s:%d: %q", ErrSymbolLine, path, line, scanner.Text())
+		}
+		symbols[address] = fields[1]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading symbols %q: %w", path, err)
+	}
+	return symbols, nil
+}
+
+func parseSymbolAddress(s string) (Address, error) {
+	bankText, addrText, ok := strings.Cut(s, ":")
+	if !ok {
+		return Address{}, ErrSymbolLine
+	}
+	bank, err := strconv.ParseUint(bankText, 16, 16)
+	if err != nil {
+		return Address{}, err
+	}
+	addr, err := strconv.ParseUint(addrText, 16, 16)
+	if err != nil {
+		return Address{}, err
+	}
+	return Address{Bank: int(bank), Addr: uint16(addr)}, nil
+}
+
+// Lookup finds the name of address. Sym files also number wram and sram banks, outside of
+// the rom the lowest bank with a name for the address is used if there is no exact match.
+func (s Symbols) Lookup(address Address) (string, bool) {
+	if name, ok := s[address]; ok {
+		return name, true
+	}
+	if address.IsRom() {
+		return "", false
+	}
+	found := Address{Bank: -1}
+	for a := range s {
+		if a.Addr == address.Addr && (found.Bank < 0 || a.Bank < found.Bank) {
+			found = a
+		}
+	}
+	if found.Bank < 0 {
+		return "", false
+	}
+	return s[found], true
+}
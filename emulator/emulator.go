@@ -1,12 +1,14 @@
 // Aims to be M-Cycle Accurate
 
-// TODO: to get tetris to work we have to have controls. Currently probably all buttons pressed which triggers automatic restart in tetris (https://emudev.de/gameboy-emulator/starting-the-first-games/)
-
 package emulator
 
 import (
+	"bytes"
+	"fmt"
 	"go-boy/internal"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -27,6 +29,8 @@ var DIV_REG_INCREMENT_HZ = 16384
 
 var ScreenSizeMultiplier int = 5
 
+var DEFAULT_SAVE_INTERVAL = 10 * time.Second
+
 var GB_WINDOW_WIDTH int = 160
 var GB_WINDOW_HEIGHT int = 144
 
@@ -73,6 +77,7 @@ type Emulator struct {
 
 	DelegateDrawToDebugger bool
 	ranMCyclesThisFrame    uint64
+	doubleSpeedCycles      uint64 // odd cpu cycle left over in double speed mode
 
 	Window   *glfw.Window
 	context  *imgui.Context
@@ -85,11 +90,56 @@ type Emulator struct {
 	Io imgui.IO
 
 	updatedThisFrame bool
+
+	// Let cartridge real-time clocks follow the host time instead of emulated cycles
+	RtcUseHostClock bool
+
+	// Called whenever the rumble motor of the cartridge is switched on or off
+	OnRumble func(on bool)
+
+	// Called when a HuC3 cartridge plays a tone on its speaker
+	OnTone func(tone uint8)
+
+	// Silences all sound, there is no APU yet so for now that is only the HuC3 tones
+	Mute bool
+
+	// Called when the cpu hangs on an illegal opcode, the PPU and timers keep running.
+	// The opcode is printed if this is not set.
+	OnLock func(pc uint16, opcode uint8)
+
+	// Tilt of MBC7 cartridges in g, driven by the keyboard (IJKL) and the first gamepad
+	// unless set through SetTilt
+	tiltX, tiltY float64
+	tiltScripted bool
+
+	// Hardware to emulate, MODEL_AUTO picks CGB for color games and DMG otherwise
+	Model internal.Model
+
+	// Boot rom to run before every game, see internal.LoadBootRom
+	BootRom []uint8
+
+	// Patch applied to every rom on load, if empty a .ips/.ups/.bps next to the rom is used
+	PatchPath string
+
+	// Image seen by the Pocket Camera, a generated test pattern if nil
+	CameraSensor internal.CameraSensor
+
+	// Infrared transceiver of HuC1/HuC3 cartridges, use internal.NewInfraredLink to connect two emulators
+	Infrared internal.InfraredPort
+
+	// Battery backed cartridge RAM is flushed to a .sav file next to the rom
+	// every SaveInterval (if it changed) and when the emulator shuts down
+	SaveInterval  time.Duration
+	DisableSaves  bool
+	SaveDir       string // if set, .sav files go here instead of next to the rom
+	lastSave      time.Time
+	lastSaveState []uint8
 }
 
 func NewEmulator() *Emulator {
 	emu := &Emulator{}
 	emu.DelegateDrawToDebugger = true
+	emu.SaveInterval = DEFAULT_SAVE_INTERVAL
 	emu.Cpu = internal.NewCpu()
 	emu.Ppu = internal.NewPpu(ScreenSizeMultiplier)
 
@@ -106,6 +156,8 @@ func NewEmulator() *Emulator {
 	emu.SetupDebugTextures()
 	emu.Restart()
 
+	emu.Window.SetDropCallback(emu.dropCallback)
+
 	return emu
 }
 
@@ -310,47 +362,367 @@ func (e *Emulator) clearTextures() {
 
 	gl.BindTexture(gl.TEXTURE_2D, 0)
 }
+
+// Restart resets the hardware and starts the current rom from the beginning
 func (e *Emulator) Restart() {
 
+	// Cpu.Restart wipes the cartridge RAM, so persist it first
+	if err := e.FlushSave(); err != nil {
+		println(err.Error())
+	}
+
+	if e.currentGame == nil {
+		e.reset(nil)
+		return
+	}
+	// a save that can not be read leaves the game running as it is
+	save, err := e.readSave(e.currentGame)
+	if err != nil {
+		println(err.Error())
+		return
+	}
+	e.reset(e.currentGame)
+	if err := e.LoadRom(e.currentGame, save); err != nil {
+		println(err.Error())
+	}
+}
+
+func (e *Emulator) reset(rom *Rom) {
 	e.clearTextures()
 
+	var header *internal.Header
+	model := e.Model
+	palette := internal.DEFAULT_PALETTE
+	if rom != nil {
+		header = &rom.Header
+		q := rom.Quirk()
+		if model == internal.MODEL_AUTO {
+			model = q.Model
+		}
+		if q.Palette != nil {
+			palette = *q.Palette
+		}
+	}
+	internal.SetPalette(palette)
+	e.Cpu.Model = model.Resolve(header)
+	e.Cpu.Header = header
+	e.Cpu.BootRom = e.BootRom
 	e.Cpu.Restart()
 	e.Ppu.Restart(ScreenSizeMultiplier)
 	e.ranMCyclesThisFrame = 0
+	e.doubleSpeedCycles = 0
 	e.updatedThisFrame = false
 	e.Ppu.Cpu = e.Cpu
 	e.Cpu.Ppu = e.Ppu
 	e.Cpu.Memory.Ppu = e.Ppu
+	e.Ppu.ConnectIo()
+	if len(e.BootRom) == 0 {
+		st := e.Cpu.Model.PowerOnState(header)
+		e.Ppu.SetPhase(st.PpuLine, st.PpuMode, st.PpuDot)
+	}
+	e.Cpu.Tick = e.tick
+	e.Cpu.Memory.Io.OnWrite(0x02, e.serialWrite)
 	e.startTime = time.Now().UnixNano()
-	e.currentGame = internal.NewRom("./games/Dr.M.gb")
-	//e.currentGame = internal.NewRom("./games/Tetris.gb")
+}
+
+// OpenRom switches to another rom, entry selects the file inside an archive (see internal.NewRomFromArchive)
+func (e *Emulator) OpenRom(path string, entry string) error {
+	return e.openRom(path, entry, e.PatchPath)
+}
+
+// openRom switches to another rom patched with patch, or with the patch found next to it if patch is empty.
+// The running game keeps going if the new rom can not be loaded.
+func (e *Emulator) openRom(path string, entry string, patch string) error {
+	rom, err := internal.NewRomFromArchive(path, entry)
+	if err != nil {
+		return err
+	}
+	if err := e.patchRom(rom, patch); err != nil {
+		return err
+	}
+	if _, err := internal.NewMbc(rom); err != nil {
+		return err
+	}
+
+	// the old game has to be saved while its cartridge is still plugged in,
+	// and before the save of the new one is read in case it is the same game
+	if err := e.FlushSave(); err != nil {
+		println(err.Error())
+	}
+	save, err := e.readSave(rom)
+	if err != nil {
+		return err
+	}
+	e.Cpu.Memory.Cart = nil
+	e.currentGame = nil
+
+	e.reset(rom)
+	if err := e.LoadRom(rom, save); err != nil {
+		return err
+	}
+	e.currentGame = rom
+
+	title := "go-boy!"
+	if rom.Header.Title != "" {
+		title += " - " + rom.Header.Title
+	}
+	e.Window.SetTitle(title)
+	return nil
+}
+
+// Dropping a rom on the window opens it, dropping a patch restarts the current rom with it
+func (e *Emulator) dropCallback(w *glfw.Window, names []string) {
+	if len(names) == 0 {
+		return
+	}
+	name := names[0]
+
+	if slices.Contains(internal.PATCH_EXTENSIONS, strings.ToLower(filepath.Ext(name))) {
+		if e.currentGame == nil {
+			return
+		}
+		// the patch only applies to this game, not to the roms opened after it
+		if err := e.openRom(e.currentGame.Path, e.currentGame.Entry, name); err != nil {
+			println(err.Error())
+		}
+		return
+	}
+
+	if err := e.OpenRom(name, ""); err != nil {
+		println(err.Error())
+	}
+}
+
+func (e *Emulator) LoadRom(r *Rom, save []byte) error {
+	if err := e.patchRom(r, e.PatchPath); err != nil {
+		return err
+	}
+	cart, err := internal.NewMbc(r)
+	if err != nil {
+		return err
+	}
+	if c, ok := cart.(internal.RtcMbc); ok && c.GetRtc() != nil {
+		c.GetRtc().UseHostClock = e.RtcUseHostClock
+	}
+	if c, ok := cart.(internal.RumbleMbc); ok {
+		c.SetRumbleCallback(e.rumble)
+	}
+	if c, ok := cart.(internal.ToneMbc); ok {
+		c.SetToneCallback(e.tone)
+	}
+	if c, ok := cart.(internal.InfraredMbc); ok {
+		c.SetInfraredPort(e.Infrared)
+	}
+	if c, ok := cart.(internal.TiltMbc); ok {
+		c.SetTilt(e.tiltX, e.tiltY)
+	}
+	if c, ok := cart.(internal.CameraMbc); ok {
+		c.SetCameraSensor(e.CameraSensor)
+	}
+	e.Cpu.Memory.Cart = cart
+
+	e.loadSave(r, save)
+	return nil
+}
+
+// patchRom soft-patches the rom in memory, the file on disk is never touched.
+// Without a patch the one next to the rom is used, roms that are already patched are left alone.
+func (e *Emulator) patchRom(r *Rom, patch string) error {
+	if r.Patch != "" {
+		return nil
+	}
+	if patch == "" {
+		patch = internal.FindPatch(r)
+	}
+	if patch == "" {
+		return nil
+	}
+	return r.ApplyPatchFile(patch)
+}
+
+// savePath returns the .sav path of the rom if it should be saved
+func (e *Emulator) savePath(r *Rom) (string, bool) {
+	if e.DisableSaves || r == nil || r.Path == "" || !r.HasBattery() {
+		return "", false
+	}
+	path := r.SavePath()
+	if e.SaveDir != "" {
+		path = filepath.Join(e.SaveDir, filepath.Base(path))
+	}
+	return path, true
+}
+
+// batterySave returns the cartridge and the .sav path if the current game should be saved
+func (e *Emulator) batterySave(r *Rom) (internal.BatteryMbc, string, bool) {
+	path, ok := e.savePath(r)
+	if !ok {
+		return nil, "", false
+	}
+	cart, ok := e.Cpu.Memory.Cart.(internal.BatteryMbc)
+	if !ok {
+		return nil, "", false
+	}
+	return cart, path, true
+}
+
+// readSave reads the .sav file of the rom, nil if there is none.
+// It is read before the running game is replaced, so a broken save does not end it.
+func (e *Emulator) readSave(r *Rom) ([]byte, error) {
+	path, ok := e.savePath(r)
+	if !ok {
+		return nil, nil
+	}
+	return internal.ReadSaveFile(path)
+}
+
+// loadSave puts the save read by readSave into the cartridge of r
+func (e *Emulator) loadSave(r *Rom, save []byte) {
+	e.lastSave = time.Now()
+	e.lastSaveState = nil
+
+	cart, _, ok := e.batterySave(r)
+	if !ok {
+		return
+	}
+	if save != nil {
+		cart.LoadSaveData(save)
+	}
+	e.lastSaveState = internal.SaveState(cart)
+}
+
+// FlushSave writes the battery backed RAM of the current game to disk if it changed since the last save
+func (e *Emulator) FlushSave() error {
+	e.lastSave = time.Now()
+
+	cart, path, ok := e.batterySave(e.currentGame)
+	if !ok {
+		return nil
+	}
+	// a clock that only ran on does not need a new save, see internal.SaveState
+	state := internal.SaveState(cart)
+	if bytes.Equal(state, e.lastSaveState) {
+		return nil
+	}
+	if err := internal.WriteSaveFile(path, cart.SaveData(time.Now())); err != nil {
+		return err
+	}
+	e.lastSaveState = state
+	return nil
+}
+
+func (e *Emulator) rumble(on bool) {
+	if e.OnRumble != nil {
+		e.OnRumble(on)
+	}
+}
+
+// SetTilt tilts MBC7 cartridges, x and y are in g (-1 to 1 is a comfortable range),
+// positive x tilts to the right and positive y towards the player.
+// While set, keyboard and gamepad input are ignored until ClearTilt is called.
+func (e *Emulator) SetTilt(x, y float64) {
+	e.tiltX, e.tiltY = x, y
+	e.tiltScripted = true
+	e.applyTilt()
+}
+
+func (e *Emulator) ClearTilt() {
+	e.tiltX, e.tiltY = 0, 0
+	e.tiltScripted = false
+	e.applyTilt()
+}
+
+func (e *Emulator) applyTilt() {
+	if c, ok := e.Cpu.Memory.Cart.(internal.TiltMbc); ok {
+		c.SetTilt(e.tiltX, e.tiltY)
+	}
+}
+
+// pollTilt reads the tilt from the keyboard or, if it is not used, the axes of the first gamepad
+func (e *Emulator) pollTilt() {
+	if e.tiltScripted || e.Window == nil {
+		return
+	}
+	if _, ok := e.Cpu.Memory.Cart.(internal.TiltMbc); !ok {
+		return
+	}
+
+	key := func(k glfw.Key) float64 {
+		if e.Window.GetKey(k) == glfw.Press {
+			return 1
+		}
+		return 0
+	}
+	e.tiltX = key(glfw.KeyL) - key(glfw.KeyJ)
+	e.tiltY = key(glfw.KeyK) - key(glfw.KeyI)
+
+	if e.tiltX == 0 && e.tiltY == 0 && glfw.Joystick1.Present() {
+		if axes := glfw.Joystick1.GetAxes(); len(axes) >= 2 {
+			e.tiltX, e.tiltY = float64(axes[0]), float64(axes[1])
+		}
+	}
+	e.applyTilt()
+}
+
+var JOYPAD_KEYS = []struct {
+	key    glfw.Key
+	button internal.JoypadButton
+}{
+	{glfw.KeyRight, internal.BUTTON_RIGHT},
+	{glfw.KeyLeft, internal.BUTTON_LEFT},
+	{glfw.KeyUp, internal.BUTTON_UP},
+	{glfw.KeyDown, internal.BUTTON_DOWN},
+	{glfw.KeyX, internal.BUTTON_A},
+	{glfw.KeyZ, internal.BUTTON_B},
+	{glfw.KeyBackspace, internal.BUTTON_SELECT},
+	{glfw.KeyEnter, internal.BUTTON_START},
+}
 
-	e.LoadRom(e.currentGame)
+func (e *Emulator) pollJoypad() {
+	if e.Window == nil {
+		return
+	}
+	var pressed internal.JoypadButton
+	for _, k := range JOYPAD_KEYS {
+		if e.Window.GetKey(k.key) == glfw.Press {
+			pressed |= k.button
+		}
+	}
+	e.Cpu.Memory.Joypad.SetPressed(pressed)
+}
 
+func (e *Emulator) lock() {
+	opcode, _ := e.Cpu.Memory.ReadByteAtForced(e.Cpu.PC)
+	if e.OnLock != nil {
+		e.OnLock(e.Cpu.PC, opcode)
+		return
+	}
+	fmt.Printf("cpu locked up at PC 0x%04x: 0x%02x is not a valid instruction\n", e.Cpu.PC, opcode)
 }
 
-func (e *Emulator) LoadRom(r *Rom) {
-	for i := 0x0; i <= 0x7fff; i++ {
-		newVal, _ := r.ReadByteAt(uint16(i))
-		e.Cpu.Memory.SetValueForRom(uint16(i), newVal)
+func (e *Emulator) tone(tone uint8) {
+	if e.OnTone != nil && !e.Mute {
+		e.OnTone(tone)
 	}
 }
 
 func (e *Emulator) RunTests(tests []string) {
 
+	// test roms must not leave .sav files behind
+	e.DisableSaves = true
+
 	var startNext bool = false
 	go changeBool(&startNext)
 	for _, test := range tests {
 		startNext = false
-		e.Restart()
-		e.currentGame = internal.NewRom(test)
-		e.LoadRom(e.currentGame)
+		if err := e.OpenRom(test, ""); err != nil {
+			println(err.Error())
+			continue
+		}
 		for {
 			if startNext {
 				println()
 				break
 			}
-			e.SerialOut()
 			e.Step()
 		}
 	}
@@ -368,12 +740,21 @@ func changeBool(startNextTest *bool) {
 func (e *Emulator) Run() {
 	for !e.Window.ShouldClose() {
 
+		// nothing to run until a rom is dropped on the window
+		if e.currentGame == nil {
+			glfw.WaitEventsTimeout(0.1)
+			continue
+		}
+
 		if e.Ppu.HandleGLUpdate {
 			glfw.PollEvents()
 		}
-		e.SerialOut()
 		e.Step()
 	}
+
+	if err := e.FlushSave(); err != nil {
+		println(err.Error())
+	}
 }
 
 func (e *Emulator) Render() {
@@ -394,30 +775,77 @@ func (e *Emulator) Render() {
 
 func (e *Emulator) Step() {
 
-	ranMCyclesThisStep := uint64(1)
-	ranMCyclesThisStep += e.handleInterrupts()
-
-	if !e.Cpu.Halt {
-		ranMCyclesThisStep += e.Cpu.Step()
+	if e.Cpu.Stop {
+		e.stepStopped()
+		return
 	}
-	e.Cpu.UpdateTimers(ranMCyclesThisStep)
 
-	e.Ppu.Step(ranMCyclesThisStep)
-	e.ranMCyclesThisFrame += ranMCyclesThisStep
+	// the cpu advances everything else itself through tick, on every M-cycle it uses
+	e.handleInterrupts()
+
+	if e.Cpu.Halt || e.Cpu.Locked {
+		e.tick(1)
+	} else {
+		e.Cpu.Step()
+		if e.Cpu.Locked {
+			e.lock()
+		}
+	}
 
 	if e.ShouldRender() {
 		e.Render()
 	}
 	if e.ranMCyclesThisFrame >= MAX_CYCLES_PER_FRAME {
-		computeTime := time.Now().UnixNano() - e.startTime
-		waitTime := time.Nanosecond * time.Duration(16742706-computeTime)
-		if waitTime > 0 {
+		e.endFrame()
+	}
+}
+
+// tick runs timers, cartridge and PPU for the M-cycles the cpu used
+func (e *Emulator) tick(mCycles uint64) {
+	e.Cpu.UpdateTimers(mCycles)
 
-			time.Sleep(waitTime)
+	// in double speed mode the cpu cycles are half as long, everything else keeps its pace
+	mCyclesRealTime := mCycles
+	if e.Cpu.DoubleSpeed {
+		e.doubleSpeedCycles += mCycles
+		mCyclesRealTime = e.doubleSpeedCycles / 2
+		e.doubleSpeedCycles %= 2
+	}
+	e.Cpu.Memory.TickCart(mCyclesRealTime)
+
+	e.Ppu.Step(mCyclesRealTime)
+	e.ranMCyclesThisFrame += mCyclesRealTime
+}
+
+// stepStopped lets time pass while the cpu is in STOP. The system clock is off, so only cartridge
+// hardware with its own crystal keeps running and the screen stays blank until a button is pressed.
+func (e *Emulator) stepStopped() {
+	if e.Cpu.Wake() {
+		return
+	}
+	e.Cpu.Memory.TickCart(MAX_CYCLES_PER_FRAME)
+	e.Render()
+	e.endFrame()
+}
+
+func (e *Emulator) endFrame() {
+	computeTime := time.Now().UnixNano() - e.startTime
+	waitTime := time.Nanosecond * time.Duration(16742706-computeTime)
+	if waitTime > 0 {
+
+		time.Sleep(waitTime)
+	}
+	e.startTime = time.Now().UnixNano()
+	e.updatedThisFrame = false
+	e.ranMCyclesThisFrame = 0
+
+	e.pollTilt()
+	e.pollJoypad()
+
+	if time.Since(e.lastSave) >= e.SaveInterval {
+		if err := e.FlushSave(); err != nil {
+			println(err.Error())
 		}
-		e.startTime = time.Now().UnixNano()
-		e.updatedThisFrame = false
-		e.ranMCyclesThisFrame = 0
 	}
 }
 
@@ -427,7 +855,8 @@ func (e *Emulator) ShouldRender() bool {
 	return e.Cpu.Memory.Io.GetLY() == 144 && !e.updatedThisFrame
 }
 
-func (e *Emulator) handleInterrupts() uint64 {
+// The dispatch takes 5 M-cycles: two waiting, two pushing PC and one to jump to the handler
+func (e *Emulator) handleInterrupts() {
 	requestedInterrupts := e.Cpu.Memory.Io.GetIF()
 	enabledInterrupts := e.Cpu.Memory.GetIe()
 	activeInterrupts := requestedInterrupts & enabledInterrupts & 0x1f
@@ -435,12 +864,24 @@ func (e *Emulator) handleInterrupts() uint64 {
 		e.Cpu.Halt = false
 	}
 
+	// a locked cpu does not respond to interrupts either
+	if e.Cpu.Locked {
+		return
+	}
+
 	if e.Cpu.IME {
 		if activeInterrupts != 0 {
+			returnPC := e.Cpu.PC
+			if e.Cpu.HaltBug {
+				// EI right before HALT: the handler returns to the HALT, which runs again
+				e.Cpu.HaltBug = false
+				returnPC--
+			}
+			e.Cpu.Idle(2)
 			e.Cpu.SP--
-			e.Cpu.Memory.SetValue(e.Cpu.SP, internal.GetHigher8(e.Cpu.PC))
+			e.Cpu.BusWrite(e.Cpu.SP, internal.GetHigher8(returnPC))
 			e.Cpu.SP--
-			e.Cpu.Memory.SetValue(e.Cpu.SP, internal.GetLower8(e.Cpu.PC))
+			e.Cpu.BusWrite(e.Cpu.SP, internal.GetLower8(returnPC))
 
 			if e.Cpu.Memory.GetInterruptEnabledBit(internal.VBLANK) && e.Cpu.Memory.Io.GetInterruptFlagBit(internal.VBLANK) {
 				e.Cpu.Memory.Io.SetInterruptFlagBit(internal.VBLANK, false)
@@ -459,20 +900,23 @@ func (e *Emulator) handleInterrupts() uint64 {
 				e.Cpu.PC = 0x0060
 			}
 			e.Cpu.IME = false
-			return 5
+			e.Cpu.Idle(1)
 		}
 	}
-	return 0
 }
 
-func (e *Emulator) SerialOut() {
-	read, _ := e.Cpu.Memory.ReadByteAt(0xff02)
-	if read == 0x81 {
-		ch, _ := e.Cpu.Memory.ReadByteAt(0xff01)
-		print(string(ch))
-		e.Cpu.Memory.SetValue(0xff02, 0x00)
-
+// serialWrite is the write hook of SC. There is no link cable, so a transfer with the internal clock
+// finishes right away, prints the byte and shifts in 0xFF from the missing other side.
+func (e *Emulator) serialWrite(value uint8) {
+	io := &e.Cpu.Memory.Io
+	io.Store(0x02, value)
+	if value&0x81 != 0x81 {
+		return
 	}
+	print(string(io.GetSB()))
+	io.SetSB(0xFF)
+	io.Store(0x02, value&^0x80)
+	io.SetInterruptFlagBit(internal.SERIAL, true)
 }
 
 func (e *Emulator) GetCurrentGame() []byte {
@@ -0,0 +1,161 @@
+package internal
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Largest rom a cartridge header can declare, anything bigger in an archive is not a rom
+const MAX_ROM_SIZE = 8 << 20
+
+var (
+	ErrNoRomInArchive       = errors.New("archive contains no .gb, .gbc or .sgb file")
+	ErrArchiveEntryNotFound = errors.New("archive entry not found")
+	ErrArchiveEntryTooBig   = errors.New("archive entry is too big to be a rom")
+)
+
+var ROM_EXTENSIONS = []string{".gb", ".gbc", ".sgb"}
+
+func isRomName(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	for _, e := range ROM_EXTENSIONS {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+func isZip(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("PK\x03\x04")) || bytes.HasPrefix(data, []byte("PK\x05\x06"))
+}
+
+func isGzip(data []byte) bool {
+	return bytes.HasPrefix(data, []byte{0x1F, 0x8B})
+}
+
+// tar headers carry "ustar" at offset 257
+func isTar(data []byte) bool {
+	return len(data) >= 262 && string(data[257:262]) == "ustar"
+}
+
+// unpackRom returns the rom inside an archive and its name. Plain roms are returned as they are with an empty name.
+// entry selects a file by its path or base name, if empty the first rom in the archive is used.
+func unpackRom(data []byte, archivePath string, entry string) ([]byte, string, error) {
+	switch {
+	case isZip(data):
+		return unpackZip(data, entry)
+	case isGzip(data):
+		return unpackGzip(data, archivePath, entry)
+	}
+	return data, "", nil
+}
+
+func entryMatches(name string, entry string) bool {
+	if entry == "" {
+		return isRomName(name)
+	}
+	return name == entry || path.Base(name) == entry
+}
+
+func missingEntry(entry string) error {
+	if entry == "" {
+		return ErrNoRomInArchive
+	}
+	return fmt.Errorf("%w: %q", ErrArchiveEntryNotFound, entry)
+}
+
+// readLimited reads a whole entry, refusing anything bigger than a rom can be
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MAX_ROM_SIZE+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MAX_ROM_SIZE {
+		return nil, ErrArchiveEntryTooBig
+	}
+	return data, nil
+}
+
+func unpackZip(data []byte, entry string) ([]byte, string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, "", err
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !entryMatches(f.Name, entry) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, "", err
+		}
+		rom, err := readLimited(rc)
+		rc.Close()
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", f.Name, err)
+		}
+		return rom, f.Name, nil
+	}
+	return nil, "", missingEntry(entry)
+}
+
+// A .gz either holds a single rom or a tar archive (.tar.gz, .tgz)
+func unpackGzip(data []byte, archivePath string, entry string) ([]byte, string, error) {
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	defer gr.Close()
+
+	// a tar of several roms can be much bigger than a single one
+	unpacked, err := io.ReadAll(io.LimitReader(gr, 16*MAX_ROM_SIZE))
+	if err != nil {
+		return nil, "", err
+	}
+	if isTar(unpacked) {
+		return unpackTar(unpacked, entry)
+	}
+
+	// the original name is stored in the gzip header, older tools leave it out
+	name := gr.Name
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(archivePath), filepath.Ext(archivePath))
+	}
+	if entry != "" && !entryMatches(name, entry) {
+		return nil, "", missingEntry(entry)
+	}
+	if len(unpacked) > MAX_ROM_SIZE {
+		return nil, "", ErrArchiveEntryTooBig
+	}
+	return unpacked, name, nil
+}
+
+func unpackTar(data []byte, entry string) ([]byte, string, error) {
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil, "", missingEntry(entry)
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if h.Typeflag != tar.TypeReg || !entryMatches(h.Name, entry) {
+			continue
+		}
+		rom, err := readLimited(tr)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", h.Name, err)
+		}
+		return rom, h.Name, nil
+	}
+}
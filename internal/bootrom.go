@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	DMG_BOOT_ROM_SIZE = 0x100 // DMG, MGB and SGB
+	CGB_BOOT_ROM_SIZE = 0x900 // CGB and AGB, 0x0100-0x01FF is left out and holds the cartridge header
+
+	// Writing to this register unmaps the boot rom until the next reset
+	BOOT_ROM_DISABLE_ADDR = 0xFF50
+)
+
+var ErrBootRomSize = errors.New("boot rom has to be 256 (DMG/MGB) or 2304 (CGB) bytes")
+
+func LoadBootRom(path string) ([]uint8, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading boot rom %q: %w", path, err)
+	}
+	if len(data) != DMG_BOOT_ROM_SIZE && len(data) != CGB_BOOT_ROM_SIZE {
+		return nil, fmt.Errorf("loading boot rom %q: %w: got %d", path, ErrBootRomSize, len(data))
+	}
+	return data, nil
+}
+
+// bootRomMapped reports whether address is currently served by the boot rom instead of the cartridge
+func (m *Mmap) bootRomMapped(address uint16) bool {
+	if !m.bootRomEnabled {
+		return false
+	}
+	if address < DMG_BOOT_ROM_SIZE {
+		return true
+	}
+	return len(m.BootRom) == CGB_BOOT_ROM_SIZE && address >= 0x200 && address < CGB_BOOT_ROM_SIZE
+}
+
+// MapBootRom overlays the boot rom on the cartridge until the game writes to 0xFF50
+func (m *Mmap) MapBootRom(bootRom []uint8) {
+	m.BootRom = bootRom
+	m.bootRomEnabled = len(bootRom) > 0
+}
+
+func (m *Mmap) BootRomEnabled() bool {
+	return m.bootRomEnabled
+}
+
+// the boot rom can not be mapped back in
+func (m *Mmap) disableBootRom(value uint8) {
+	if value != 0 {
+		m.bootRomEnabled = false
+	}
+	m.Io.Store(BOOT_ROM_DISABLE_ADDR-0xFF00, value)
+}
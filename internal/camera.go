@@ -0,0 +1,217 @@
+package internal
+
+import "math"
+
+const (
+	CAMERA_WIDTH  = 128
+	CAMERA_HEIGHT = 112
+
+	// The last captured image is stored as 16x14 tiles at 0xA100 of RAM bank 0
+	CAMERA_IMAGE_OFFSET = 0x100
+	CAMERA_IMAGE_SIZE   = CAMERA_WIDTH * CAMERA_HEIGHT / 4
+
+	// Writing a RAM bank with this bit set maps the registers to 0xA000-0xA07F (mirrored up to 0xBFFF)
+	CAMERA_REGISTER_BANK = 0x10
+)
+
+// M64282FP image sensor registers, as seen by the game
+const (
+	CAMERA_REG_CAPTURE   = 0x00 // bit 0: start capture / busy, bits 1-2: 1D filter mode
+	CAMERA_REG_GAIN      = 0x01 // bits 0-4: gain, bits 5-7: edge enhancement mode, 0b111 enables it
+	CAMERA_REG_EXPOSURE  = 0x02 // exposure time, high byte first
+	CAMERA_REG_EDGE      = 0x04 // bits 0-2: output voltage, bit 3: invert, bits 4-6: edge enhancement ratio
+	CAMERA_REG_ZERO      = 0x05 // zero point calibration and output offset
+	CAMERA_REG_DITHER    = 0x06 // 4x4 matrix of 3 thresholds each, up to 0x35
+	CAMERA_REGISTER_SIZE = 0x36
+)
+
+// Source of the light hitting the sensor, so captures don't depend on a webcam
+type CameraSensor interface {
+	// Brightness of the pixel, 0 is black and 255 white.
+	// x is in 0-CAMERA_WIDTH-1, y in 0-CAMERA_HEIGHT-1
+	Pixel(x, y int) uint8
+}
+
+type CameraMbc interface {
+	SetCameraSensor(sensor CameraSensor)
+}
+
+var cameraEdgeRatios = [8]float64{0.5, 0.75, 1, 1.25, 2, 3, 4, 5}
+
+// https://gbdev.io/pandocs/Gameboy_Camera.html
+type Camera struct {
+	mbcBase
+
+	registersMapped bool
+	registers       [CAMERA_REGISTER_SIZE]uint8
+	captureCycles   uint64 // M-cycles left until the running capture finishes
+
+	sensor CameraSensor
+}
+
+func NewCamera(rom *Rom) *Camera {
+	m := &Camera{mbcBase: newMbcBase(rom)}
+	m.sensor = TestPatternSensor{}
+	return m
+}
+
+func (m *Camera) SetCameraSensor(sensor CameraSensor) {
+	if sensor == nil {
+		sensor = TestPatternSensor{}
+	}
+	m.sensor = sensor
+}
+
+func (m *Camera) WriteRom(address uint16, value uint8) {
+	switch {
+	case address < 0x2000:
+		// only writes are gated, the RAM can always be read
+		m.ramEnabled = value&0x0F == 0x0A
+
+	case address < 0x4000:
+		m.romBankN = int(value & 0x3F)
+
+	case address < 0x6000:
+		m.registersMapped = value&CAMERA_REGISTER_BANK != 0
+		m.ramBank = int(value & 0x0F)
+	}
+}
+
+func (m *Camera) ReadRam(address uint16) uint8 {
+	if m.registersMapped {
+		// all registers but the first are write only
+		if (address-0xA000)&0x7F == CAMERA_REG_CAPTURE {
+			return m.registers[CAMERA_REG_CAPTURE]
+		}
+		return 0x00
+	}
+	if len(m.ram) == 0 {
+		return 0xFF
+	}
+	return m.ram[m.ramOffset(address)]
+}
+
+func (m *Camera) WriteRam(address uint16, value uint8) {
+	if !m.registersMapped {
+		m.mbcBase.WriteRam(address, value)
+		return
+	}
+
+	reg := (address - 0xA000) & 0x7F
+	switch {
+	case reg == CAMERA_REG_CAPTURE:
+		value &= 0x07
+		start := GetBit(value, 0) && !m.capturing()
+		m.registers[CAMERA_REG_CAPTURE] = value
+		if start {
+			m.captureCycles = m.captureDuration()
+		} else if !GetBit(value, 0) {
+			// clearing the bit cancels the capture
+			m.captureCycles = 0
+		}
+	case reg < CAMERA_REGISTER_SIZE:
+		m.registers[reg] = value
+	}
+}
+
+func (m *Camera) capturing() bool {
+	return GetBit(m.registers[CAMERA_REG_CAPTURE], 0)
+}
+
+func (m *Camera) exposure() uint16 {
+	return uint16(m.registers[CAMERA_REG_EXPOSURE])<<8 | uint16(m.registers[CAMERA_REG_EXPOSURE+1])
+}
+
+// The sensor reads out 32446 M-cycles plus the exposure time, which counts in 16 M-cycle steps
+func (m *Camera) captureDuration() uint64 {
+	cycles := uint64(32446) + 16*uint64(m.exposure())
+	// without the N flag an extra clocked pre-charge phase runs first
+	if !GetBit(m.registers[CAMERA_REG_GAIN], 7) {
+		cycles += 512
+	}
+	return cycles
+}
+
+func (m *Camera) Tick(mCycles uint64) {
+	if !m.capturing() {
+		return
+	}
+	if mCycles < m.captureCycles {
+		m.captureCycles -= mCycles
+		return
+	}
+	m.captureCycles = 0
+	m.capture()
+	SetBit(&m.registers[CAMERA_REG_CAPTURE], 0, false)
+}
+
+// capture runs the sensor image through exposure, gain, edge enhancement and the dither matrix
+// and stores the resulting 2bpp tiles in RAM
+func (m *Camera) capture() {
+	if len(m.ram) < CAMERA_IMAGE_OFFSET+CAMERA_IMAGE_SIZE {
+		return
+	}
+
+	var levels [CAMERA_WIDTH * CAMERA_HEIGHT]float64
+	for y := range CAMERA_HEIGHT {
+		for x := range CAMERA_WIDTH {
+			levels[y*CAMERA_WIDTH+x] = m.sensorLevel(x, y)
+		}
+	}
+
+	image := m.ram[CAMERA_IMAGE_OFFSET : CAMERA_IMAGE_OFFSET+CAMERA_IMAGE_SIZE]
+	clear(image)
+	for y := range CAMERA_HEIGHT {
+		for x := range CAMERA_WIDTH {
+			color := m.dither(x, y, m.enhance(levels[:], x, y))
+			// 16 tiles per row, 16 bytes per tile, 2 bytes per tile row
+			i := (y/8*16+x/8)*16 + y%8*2
+			bit := uint8(7 - x%8)
+			image[i] |= (color & 0x01) << bit
+			image[i+1] |= (color >> 1) << bit
+		}
+	}
+}
+
+// sensorLevel is the voltage of one cell after the exposure and the amplifier, in 0-255 units
+func (m *Camera) sensorLevel(x, y int) float64 {
+	light := float64(m.sensor.Pixel(x, y))
+	// an exposure of 0x1000 passes the light through unchanged
+	level := light * float64(m.exposure()) / 0x1000
+	// the amplifier gain goes from 14 dB to 45.5 dB in 32 steps, relative to the lowest setting
+	gainDb := float64(m.registers[CAMERA_REG_GAIN]&0x1F) * 31.5 / 31
+	return level * math.Pow(10, gainDb/20)
+}
+
+// enhance applies the 2D edge enhancement filter: the pixel is boosted by the difference to its 4 neighbours
+func (m *Camera) enhance(levels []float64, x, y int) float64 {
+	at := func(x, y int) float64 {
+		x = max(0, min(CAMERA_WIDTH-1, x))
+		y = max(0, min(CAMERA_HEIGHT-1, y))
+		return levels[y*CAMERA_WIDTH+x]
+	}
+	level := at(x, y)
+	if m.registers[CAMERA_REG_GAIN]&0xE0 == 0xE0 {
+		ratio := cameraEdgeRatios[m.registers[CAMERA_REG_EDGE]>>4&0x07]
+		level += ratio * (4*level - at(x-1, y) - at(x+1, y) - at(x, y-1) - at(x, y+1))
+	}
+	if GetBit(m.registers[CAMERA_REG_EDGE], 3) {
+		level = 255 - level
+	}
+	return level
+}
+
+// dither compares the level against the 3 thresholds of the matrix cell, the brighter the lower the color
+func (m *Camera) dither(x, y int, level float64) uint8 {
+	cell := CAMERA_REG_DITHER + (y%4*4+x%4)*3
+	thresholds := m.registers[cell : cell+3]
+	switch {
+	case level < float64(thresholds[0]):
+		return 3
+	case level < float64(thresholds[1]):
+		return 2
+	case level < float64(thresholds[2]):
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"image"
+	"image/color"
+	_ "image/png"
+	"os"
+)
+
+// Generated sensor input: a diagonal gradient with a checkerboard in the middle,
+// so all four shades and some edges show up in a capture
+type TestPatternSensor struct{}
+
+func (TestPatternSensor) Pixel(x, y int) uint8 {
+	if x >= 32 && x < 96 && y >= 28 && y < 84 {
+		if (x/8+y/8)%2 == 0 {
+			return 0xFF
+		}
+		return 0x00
+	}
+	return uint8((x + y) * 255 / (CAMERA_WIDTH + CAMERA_HEIGHT - 2))
+}
+
+// Sensor input from a still image, scaled to the sensor size and converted to grayscale
+type ImageSensor struct {
+	pixels [CAMERA_WIDTH * CAMERA_HEIGHT]uint8
+}
+
+func NewImageSensor(img image.Image) *ImageSensor {
+	s := &ImageSensor{}
+	b := img.Bounds()
+	for y := range CAMERA_HEIGHT {
+		for x := range CAMERA_WIDTH {
+			// nearest neighbour is good enough for a 128x112 sensor
+			src := img.At(b.Min.X+x*b.Dx()/CAMERA_WIDTH, b.Min.Y+y*b.Dy()/CAMERA_HEIGHT)
+			s.pixels[y*CAMERA_WIDTH+x] = color.GrayModel.Convert(src).(color.Gray).Y
+		}
+	}
+	return s
+}
+
+func LoadImageSensor(path string) (*ImageSensor, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	return NewImageSensor(img), nil
+}
+
+func (s *ImageSensor) Pixel(x, y int) uint8 {
+	return s.pixels[y*CAMERA_WIDTH+x]
+}
@@ -1,10 +1,15 @@
 package internal
 
-func (cpu *Cpu) handleCB() (cycles uint64) {
+func (cpu *Cpu) handleCB() {
 	cpu.PC++
-	data, numReadBytes := cpu.Memory.ReadByteAt(cpu.PC)
+	data, numReadBytes := cpu.busRead(cpu.PC)
 	cpu.PC += numReadBytes
+	cpu.op = &CB_OPCODES[data]
+	cpu.op.exec(cpu)
+}
 
+// cbExec picks the implementation of a cb opcode, it runs after the prefix and the opcode are read
+func cbExec(data uint8) func(cpu *Cpu) {
 	instr := data >> 6
 	operand := data & 0x07
 
@@ -15,48 +20,48 @@ func (cpu *Cpu) handleCB() (cycles uint64) {
 		switch actualInstr {
 		case 0:
 			//rlc r8
-			return cpu.cbRlc(Reg8(operand))
+			return func(cpu *Cpu) { cpu.cbRlc(Reg8(operand)) }
 		case 1:
 			// rrc r8
-			return cpu.cbRrc(Reg8(operand))
+			return func(cpu *Cpu) { cpu.cbRrc(Reg8(operand)) }
 		case 2:
 			//rl r8
-			return cpu.cbRl(Reg8(operand))
+			return func(cpu *Cpu) { cpu.cbRl(Reg8(operand)) }
 		case 3:
 			//rr r8
-			return cpu.cbRr(Reg8(operand))
+			return func(cpu *Cpu) { cpu.cbRr(Reg8(operand)) }
 		case 4:
 			// sla r8
-			return cpu.cbSla(Reg8(operand))
+			return func(cpu *Cpu) { cpu.cbSla(Reg8(operand)) }
 		case 5:
 			// sra r8
-			return cpu.cbSra(Reg8(operand))
+			return func(cpu *Cpu) { cpu.cbSra(Reg8(operand)) }
 		case 6:
 			// swap r8
-			return cpu.cbSwap(Reg8(operand))
+			return func(cpu *Cpu) { cpu.cbSwap(Reg8(operand)) }
 		case 7:
 			//srl r8
-			return cpu.cbSrl(Reg8(operand))
+			return func(cpu *Cpu) { cpu.cbSrl(Reg8(operand)) }
 		default:
 			//No Opportunity for missing instructions
-			return 0
+			return nil
 		}
 	case 1:
 		bitIndex := (data >> 3) & 0x07
-		return cpu.cbBit(bitIndex, Reg8(operand))
+		return func(cpu *Cpu) { cpu.cbBit(bitIndex, Reg8(operand)) }
 	case 2:
 		bitIndex := (data >> 3) & 0x07
-		return cpu.cbResetRegBit(bitIndex, Reg8(operand))
+		return func(cpu *Cpu) { cpu.cbResetRegBit(bitIndex, Reg8(operand)) }
 	case 3:
 		bitIndex := (data >> 3) & 0x07
-		return cpu.cbSetRegBit(bitIndex, Reg8(operand))
+		return func(cpu *Cpu) { cpu.cbSetRegBit(bitIndex, Reg8(operand)) }
 	default:
 		//No Opportunity for missing instructions
-		return 0
+		return nil
 	}
 }
 
-func (cpu *Cpu) cbSwap(operand Reg8) uint64 {
+func (cpu *Cpu) cbSwap(operand Reg8) {
 	var ptr *uint8
 
 	switch operand {
@@ -75,17 +80,17 @@ func (cpu *Cpu) cbSwap(operand Reg8) uint64 {
 	case REG_L:
 		ptr = &cpu.L
 	case REG_MEM_HL:
-		regVal, _ := cpu.Memory.ReadByteAt(cpu.GetHL())
+		regVal, _ := cpu.busRead(cpu.GetHL())
 		lower := getLower4(regVal)
 		higher := getHigher4(regVal)
 		regVal = lower<<4 | higher
-		cpu.Memory.SetValue(cpu.GetHL(), regVal)
+		cpu.busWrite(cpu.GetHL(), regVal)
 
 		cpu.SetZeroFlag(regVal == 0)
 		cpu.SetSubFlag(false)
 		cpu.SetHalfCarryFlag(false)
 		cpu.SetCarryFlag(false)
-		return 4
+		return
 	}
 
 	lower := getLower4(*ptr)
@@ -96,10 +101,9 @@ func (cpu *Cpu) cbSwap(operand Reg8) uint64 {
 	cpu.SetSubFlag(false)
 	cpu.SetHalfCarryFlag(false)
 	cpu.SetCarryFlag(false)
-	return 2
 }
 
-func (cpu *Cpu) cbBit(bitIndex uint8, operand Reg8) uint64 {
+func (cpu *Cpu) cbBit(bitIndex uint8, operand Reg8) {
 
 	var regData uint8
 
@@ -120,24 +124,22 @@ func (cpu *Cpu) cbBit(bitIndex uint8, operand Reg8) uint64 {
 	case REG_L:
 		regData = cpu.L
 	case REG_MEM_HL:
-		regData, _ := cpu.Memory.ReadByteAt(cpu.GetHL())
+		regData, _ := cpu.busRead(cpu.GetHL())
 		bit := GetBit(regData, bitIndex)
 
 		cpu.SetZeroFlag(!bit)
 		cpu.SetSubFlag(false)
 		cpu.SetHalfCarryFlag(true)
 
-		return 3
+		return
 	}
 	bit := GetBit(regData, bitIndex)
 
 	cpu.SetZeroFlag(!bit)
 	cpu.SetSubFlag(false)
 	cpu.SetHalfCarryFlag(true)
-
-	return 2
 }
-func (cpu *Cpu) cbResetRegBit(bitIndex uint8, operand Reg8) uint64 {
+func (cpu *Cpu) cbResetRegBit(bitIndex uint8, operand Reg8) {
 	var ptr *uint8
 
 	switch operand {
@@ -156,16 +158,15 @@ func (cpu *Cpu) cbResetRegBit(bitIndex uint8, operand Reg8) uint64 {
 	case REG_L:
 		ptr = &cpu.L
 	case REG_MEM_HL:
-		addr, _ := cpu.Memory.ReadByteAt(cpu.GetHL())
+		addr, _ := cpu.busRead(cpu.GetHL())
 		SetBit(&addr, bitIndex, false)
-		cpu.Memory.SetValue(cpu.GetHL(), addr)
-		return 4
+		cpu.busWrite(cpu.GetHL(), addr)
+		return
 	}
 	SetBit(ptr, bitIndex, false)
-	return 2
 }
 
-func (cpu *Cpu) cbSetRegBit(bitIndex uint8, operand Reg8) uint64 {
+func (cpu *Cpu) cbSetRegBit(bitIndex uint8, operand Reg8) {
 	var ptr *uint8
 
 	switch operand {
@@ -184,16 +185,15 @@ func (cpu *Cpu) cbSetRegBit(bitIndex uint8, operand Reg8) uint64 {
 	case REG_L:
 		ptr = &cpu.L
 	case REG_MEM_HL:
-		regVal, _ := cpu.Memory.ReadByteAt(cpu.GetHL())
+		regVal, _ := cpu.busRead(cpu.GetHL())
 		SetBit(&regVal, bitIndex, true)
-		cpu.Memory.SetValue(cpu.GetHL(), regVal)
-		return 4
+		cpu.busWrite(cpu.GetHL(), regVal)
+		return
 	}
 	SetBit(ptr, bitIndex, true)
-	return 2
 }
 
-func (cpu *Cpu) cbRlc(operand Reg8) uint64 {
+func (cpu *Cpu) cbRlc(operand Reg8) {
 
 	var ptr *uint8
 
@@ -213,17 +213,17 @@ func (cpu *Cpu) cbRlc(operand Reg8) uint64 {
 	case REG_L:
 		ptr = &cpu.L
 	case REG_MEM_HL:
-		regVal, _ := cpu.Memory.ReadByteAt(cpu.GetHL())
+		regVal, _ := cpu.busRead(cpu.GetHL())
 		newCarry := GetBit(regVal, 7)
 		regVal <<= 1
 		SetBit(&regVal, 0, newCarry)
-		cpu.Memory.SetValue(cpu.GetHL(), regVal)
+		cpu.busWrite(cpu.GetHL(), regVal)
 
 		cpu.SetZeroFlag(regVal == 0)
 		cpu.SetSubFlag(false)
 		cpu.SetHalfCarryFlag(false)
 		cpu.SetCarryFlag(newCarry)
-		return 4
+		return
 	}
 
 	newCarry := GetBit(*ptr, 7)
@@ -234,11 +234,9 @@ func (cpu *Cpu) cbRlc(operand Reg8) uint64 {
 	cpu.SetSubFlag(false)
 	cpu.SetHalfCarryFlag(false)
 	cpu.SetCarryFlag(newCarry)
-
-	return 2
 }
 
-func (cpu *Cpu) cbRrc(operand Reg8) uint64 {
+func (cpu *Cpu) cbRrc(operand Reg8) {
 
 	var ptr *uint8
 
@@ -258,17 +256,17 @@ func (cpu *Cpu) cbRrc(operand Reg8) uint64 {
 	case REG_L:
 		ptr = &cpu.L
 	case REG_MEM_HL:
-		regVal, _ := cpu.Memory.ReadByteAt(cpu.GetHL())
+		regVal, _ := cpu.busRead(cpu.GetHL())
 		newCarry := GetBit(regVal, 0)
 		regVal >>= 1
 		SetBit(&regVal, 7, newCarry)
-		cpu.Memory.SetValue(cpu.GetHL(), regVal)
+		cpu.busWrite(cpu.GetHL(), regVal)
 
 		cpu.SetZeroFlag(regVal == 0)
 		cpu.SetSubFlag(false)
 		cpu.SetHalfCarryFlag(false)
 		cpu.SetCarryFlag(newCarry)
-		return 4
+		return
 	}
 
 	newCarry := GetBit(*ptr, 0)
@@ -279,11 +277,9 @@ func (cpu *Cpu) cbRrc(operand Reg8) uint64 {
 	cpu.SetSubFlag(false)
 	cpu.SetHalfCarryFlag(false)
 	cpu.SetCarryFlag(newCarry)
-
-	return 2
 }
 
-func (cpu *Cpu) cbRl(operand Reg8) uint64 {
+func (cpu *Cpu) cbRl(operand Reg8) {
 
 	var ptr *uint8
 
@@ -303,17 +299,17 @@ func (cpu *Cpu) cbRl(operand Reg8) uint64 {
 	case REG_L:
 		ptr = &cpu.L
 	case REG_MEM_HL:
-		regVal, _ := cpu.Memory.ReadByteAt(cpu.GetHL())
+		regVal, _ := cpu.busRead(cpu.GetHL())
 		newCarry := GetBit(regVal, 7)
 		regVal <<= 1
 		SetBit(&regVal, 0, cpu.GetCarryFlag() == 1)
-		cpu.Memory.SetValue(cpu.GetHL(), regVal)
+		cpu.busWrite(cpu.GetHL(), regVal)
 
 		cpu.SetZeroFlag(regVal == 0)
 		cpu.SetSubFlag(false)
 		cpu.SetHalfCarryFlag(false)
 		cpu.SetCarryFlag(newCarry)
-		return 4
+		return
 	}
 
 	newCarry := GetBit(*ptr, 7)
@@ -324,11 +320,9 @@ func (cpu *Cpu) cbRl(operand Reg8) uint64 {
 	cpu.SetSubFlag(false)
 	cpu.SetHalfCarryFlag(false)
 	cpu.SetCarryFlag(newCarry)
-
-	return 2
 }
 
-func (cpu *Cpu) cbRr(operand Reg8) uint64 {
+func (cpu *Cpu) cbRr(operand Reg8) {
 
 	var ptr *uint8
 
@@ -348,17 +342,17 @@ func (cpu *Cpu) cbRr(operand Reg8) uint64 {
 	case REG_L:
 		ptr = &cpu.L
 	case REG_MEM_HL:
-		regVal, _ := cpu.Memory.ReadByteAt(cpu.GetHL())
+		regVal, _ := cpu.busRead(cpu.GetHL())
 		newCarry := GetBit(regVal, 0)
 		regVal >>= 1
 		SetBit(&regVal, 7, cpu.GetCarryFlag() == 1)
-		cpu.Memory.SetValue(cpu.GetHL(), regVal)
+		cpu.busWrite(cpu.GetHL(), regVal)
 
 		cpu.SetZeroFlag(regVal == 0)
 		cpu.SetSubFlag(false)
 		cpu.SetHalfCarryFlag(false)
 		cpu.SetCarryFlag(newCarry)
-		return 4
+		return
 	}
 
 	newCarry := GetBit(*ptr, 0)
@@ -369,11 +363,9 @@ func (cpu *Cpu) cbRr(operand Reg8) uint64 {
 	cpu.SetSubFlag(false)
 	cpu.SetHalfCarryFlag(false)
 	cpu.SetCarryFlag(newCarry)
-
-	return 2
 }
 
-func (cpu *Cpu) cbSla(operand Reg8) uint64 {
+func (cpu *Cpu) cbSla(operand Reg8) {
 
 	var ptr *uint8
 
@@ -393,17 +385,17 @@ func (cpu *Cpu) cbSla(operand Reg8) uint64 {
 	case REG_L:
 		ptr = &cpu.L
 	case REG_MEM_HL:
-		regVal, _ := cpu.Memory.ReadByteAt(cpu.GetHL())
+		regVal, _ := cpu.busRead(cpu.GetHL())
 		newCarry := GetBit(regVal, 7)
 		regVal <<= 1
 		SetBit(&regVal, 0, false)
-		cpu.Memory.SetValue(cpu.GetHL(), regVal)
+		cpu.busWrite(cpu.GetHL(), regVal)
 
 		cpu.SetZeroFlag(regVal == 0)
 		cpu.SetSubFlag(false)
 		cpu.SetHalfCarryFlag(false)
 		cpu.SetCarryFlag(newCarry)
-		return 4
+		return
 	}
 
 	newCarry := GetBit(*ptr, 7)
@@ -414,11 +406,9 @@ func (cpu *Cpu) cbSla(operand Reg8) uint64 {
 	cpu.SetSubFlag(false)
 	cpu.SetHalfCarryFlag(false)
 	cpu.SetCarryFlag(newCarry)
-
-	return 2
 }
 
-func (cpu *Cpu) cbSrl(operand Reg8) uint64 {
+func (cpu *Cpu) cbSrl(operand Reg8) {
 
 	var ptr *uint8
 
@@ -438,17 +428,17 @@ func (cpu *Cpu) cbSrl(operand Reg8) uint64 {
 	case REG_L:
 		ptr = &cpu.L
 	case REG_MEM_HL:
-		regVal, _ := cpu.Memory.ReadByteAt(cpu.GetHL())
+		regVal, _ := cpu.busRead(cpu.GetHL())
 		newCarry := GetBit(regVal, 0)
 		regVal >>= 1
 		SetBit(&regVal, 7, false)
-		cpu.Memory.SetValue(cpu.GetHL(), regVal)
+		cpu.busWrite(cpu.GetHL(), regVal)
 
 		cpu.SetZeroFlag(regVal == 0)
 		cpu.SetSubFlag(false)
 		cpu.SetHalfCarryFlag(false)
 		cpu.SetCarryFlag(newCarry)
-		return 4
+		return
 	}
 
 	newCarry := GetBit(*ptr, 0)
@@ -459,11 +449,9 @@ func (cpu *Cpu) cbSrl(operand Reg8) uint64 {
 	cpu.SetSubFlag(false)
 	cpu.SetHalfCarryFlag(false)
 	cpu.SetCarryFlag(newCarry)
-
-	return 2
 }
 
-func (cpu *Cpu) cbSra(operand Reg8) uint64 {
+func (cpu *Cpu) cbSra(operand Reg8) {
 
 	var ptr *uint8
 
@@ -483,18 +471,18 @@ func (cpu *Cpu) cbSra(operand Reg8) uint64 {
 	case REG_L:
 		ptr = &cpu.L
 	case REG_MEM_HL:
-		regVal, _ := cpu.Memory.ReadByteAt(cpu.GetHL())
+		regVal, _ := cpu.busRead(cpu.GetHL())
 		newCarry := GetBit(regVal, 0)
 		oldMSB := GetBit(regVal, 7)
 		regVal >>= 1
 		SetBit(&regVal, 7, oldMSB)
-		cpu.Memory.SetValue(cpu.GetHL(), regVal)
+		cpu.busWrite(cpu.GetHL(), regVal)
 
 		cpu.SetZeroFlag(regVal == 0)
 		cpu.SetSubFlag(false)
 		cpu.SetHalfCarryFlag(false)
 		cpu.SetCarryFlag(newCarry)
-		return 4
+		return
 	}
 
 	newCarry := GetBit(*ptr, 0)
@@ -506,6 +494,4 @@ func (cpu *Cpu) cbSra(operand Reg8) uint64 {
 	cpu.SetSubFlag(false)
 	cpu.SetHalfCarryFlag(false)
 	cpu.SetCarryFlag(newCarry)
-
-	return 2
 }
@@ -30,13 +30,70 @@ type Cpu struct {
 
 	Halt    bool
 	HaltBug bool
-	Stop    bool
+	Stop    bool // the system clock is off until a button is pressed, see Wake
+	Locked  bool // an illegal opcode hangs the cpu until the next restart, PC stays on it
+
+	// CGB double speed mode, the cpu and timers run twice as fast as the PPU
+	DoubleSpeed bool
 
 	Ppu *Ppu
+
+	// Advances everything but the cpu (timers, PPU, cartridge) by some M-cycles.
+	// It runs before every bus access, so reads and writes see the rest of the system
+	// as it is in the M-cycle they happen in.
+	Tick            func(mCycles uint64)
+	mCyclesThisStep uint64
+
+	// The instruction being run, the CB prefix swaps in the CB_OPCODES entry. Its M-cycles
+	// come from the table, BranchCycles if a conditional jump, call or return set branched
+	// (the unconditional ones share the code and have no BranchCycles).
+	op       *Opcode
+	branched bool
+
+	// Hardware model and the cartridge header (nil if there is no cartridge),
+	// they decide the register values when starting without a boot rom
+	Model  Model
+	Header *Header
+
+	// Executed from PC=0 on every restart if set, otherwise the cpu starts at 0x100
+	// in the state the boot rom leaves behind
+	BootRom []uint8
 }
 
 var IO_START_ADDR uint16 = 0xff00
 
+type CpuStatus uint8
+
+const (
+	CPU_RUNNING CpuStatus = iota
+	CPU_HALTED
+	CPU_STOPPED
+	CPU_LOCKED
+)
+
+var cpuStatusNames = map[CpuStatus]string{
+	CPU_RUNNING: "running",
+	CPU_HALTED:  "halted",
+	CPU_STOPPED: "stopped",
+	CPU_LOCKED:  "locked",
+}
+
+func (s CpuStatus) String() string {
+	return cpuStatusNames[s]
+}
+
+func (c *Cpu) Status() CpuStatus {
+	switch {
+	case c.Locked:
+		return CPU_LOCKED
+	case c.Stop:
+		return CPU_STOPPED
+	case c.Halt:
+		return CPU_HALTED
+	}
+	return CPU_RUNNING
+}
+
 func NewCpu() *Cpu {
 	cpu := &Cpu{}
 	cpu.Restart()
@@ -45,58 +102,115 @@ func NewCpu() *Cpu {
 
 func (cpu *Cpu) Restart() {
 
-	cpu.Memory = &Mmap{}
+	cpu.Memory = NewMmap()
+	cpu.Memory.Io.OnWrite(0x04, cpu.resetDiv)
 
 	cpu.Memory.Ppu = cpu.Ppu
+	cpu.Memory.Io.Model = cpu.Model
 
-	cpu.A = 0x01
-	cpu.F = 0xB0
-	cpu.B = 0x00
-	cpu.C = 0x13
-	cpu.D = 0x00
-	cpu.E = 0xD8
-	cpu.H = 0x01
-	cpu.L = 0x4D
-	cpu.SP = 0xFFFE
-	cpu.PC = 0x100
 	cpu.Halt = false
+	cpu.HaltBug = false
 	cpu.Stop = false
+	cpu.Locked = false
+	cpu.DoubleSpeed = false
 	cpu.IME = false
 	cpu.pendingIME = false
 	cpu.setIMETrueIn = 0
 
-	cpu.Memory.SetValue(0xFF05, 0x00) // TIMA
-	cpu.Memory.SetValue(0xFF06, 0x00) // TMA
-	cpu.Memory.SetValue(0xFF07, 0x00) // TAC
-	cpu.Memory.SetValue(0xFF10, 0x80) // NR10
-	cpu.Memory.SetValue(0xFF11, 0xBF) // NR11
-	cpu.Memory.SetValue(0xFF12, 0xF3) // NR12
-	cpu.Memory.SetValue(0xFF14, 0xBF) // NR14
-	cpu.Memory.SetValue(0xFF16, 0x3F) // NR21
-	cpu.Memory.SetValue(0xFF17, 0x00) // NR22
-	cpu.Memory.SetValue(0xFF19, 0xBF) // NR24
-	cpu.Memory.SetValue(0xFF1A, 0x7F) // NR30
-	cpu.Memory.SetValue(0xFF1B, 0xFF) // NR31
-	cpu.Memory.SetValue(0xFF1C, 0x9F) // NR32
-	cpu.Memory.SetValue(0xFF1E, 0xBF) // NR33
-	cpu.Memory.SetValue(0xFF20, 0xFF) // NR41
-	cpu.Memory.SetValue(0xFF21, 0x00) // NR42
-	cpu.Memory.SetValue(0xFF22, 0x00) // NR43
-	cpu.Memory.SetValue(0xFF23, 0xBF) // NR30
-	cpu.Memory.SetValue(0xFF24, 0x77) // NR50
-	cpu.Memory.SetValue(0xFF25, 0xF3) // NR51
-	cpu.Memory.SetValue(0xFF26, 0xF1) // NR52 (GB)
-	cpu.Memory.SetValue(0xFF40, 0x91) // LCDC
-	cpu.Memory.SetValue(0xFF42, 0x00) // SCY
-	cpu.Memory.SetValue(0xFF43, 0x00) // SCX
-	cpu.Memory.SetValue(0xFF45, 0x00) // LYC
-	cpu.Memory.SetValue(0xFF47, 0xFC) // BGP
-	cpu.Memory.SetValue(0xFF48, 0xFF) // OBP0
-	cpu.Memory.SetValue(0xFF49, 0xFF) // OBP1
-	cpu.Memory.SetValue(0xFF4A, 0x00) // WY
-	cpu.Memory.SetValue(0xFF4B, 0x00) // WX
-	cpu.Memory.SetValue(0xFFFF, 0x00) // IE
+	if len(cpu.BootRom) > 0 {
+		// the boot rom sets up everything itself
+		cpu.A, cpu.F, cpu.B, cpu.C, cpu.D, cpu.E, cpu.H, cpu.L = 0, 0, 0, 0, 0, 0, 0, 0
+		cpu.SP = 0x0000
+		cpu.PC = 0x0000
+		cpu.Memory.MapBootRom(cpu.BootRom)
+		cpu.Memory.Io.Regs[0x00] = 0xCF // no buttons pressed
+		return
+	}
+
+	st := cpu.Model.PowerOnState(cpu.Header)
+	cpu.A, cpu.F, cpu.B, cpu.C, cpu.D, cpu.E, cpu.H, cpu.L = st.A, st.F, st.B, st.C, st.D, st.E, st.H, st.L
+	cpu.SP = st.SP
+	cpu.PC = st.PC
+
+	// written directly, going through SetValue would start an OAM DMA for 0xFF46
+	for address, value := range st.Io {
+		if address == 0xFFFF {
+			cpu.Memory.Ie = value
+			continue
+		}
+		cpu.Memory.Io.Regs[address-IO_START_ADDR] = value
+	}
+	cpu.Memory.Io.Regs[0x04] = uint8(st.DivCounter >> 8)
+	cpu.divMCycleCounter = uint64(st.DivCounter&0xFF) / 4
+}
+
+// Idle lets M-cycles pass in which the cpu does not access the bus
+func (c *Cpu) Idle(mCycles uint64) {
+	c.mCyclesThisStep += mCycles
+	if c.Tick != nil {
+		c.Tick(mCycles)
+	}
+}
+
+// Every bus access takes one M-cycle
+func (c *Cpu) busRead(address uint16) (uint8, uint16) {
+	c.Idle(1)
+	return c.Memory.ReadByteAt(address)
+}
+
+// little endian, low byte first
+func (c *Cpu) busRead16(address uint16) (uint16, uint16) {
+	lower, _ := c.busRead(address)
+	higher, _ := c.busRead(address + 1)
+	return uint16(higher)<<8 | uint16(lower), 2
+}
+
+func (c *Cpu) busWrite(address uint16, value uint8) {
+	c.Idle(1)
+	c.Memory.SetValue(address, value)
+}
+
+// BusWrite writes like an instruction does, for cpu work outside of Step like the interrupt dispatch
+func (c *Cpu) BusWrite(address uint16, value uint8) {
+	c.busWrite(address, value)
+}
+
+// Writing any value to DIV resets it together with the rest of the internal counter,
+// which restarts the current TIMA period as well
+func (c *Cpu) resetDiv(value uint8) {
+	c.Memory.Io.Regs[0x04] = 0
+	c.divMCycleCounter = 0
+	c.timerTotalMCycles = 0
+}
+
+// STOP resets DIV and either switches the CGB speed, if KEY1 asks for it, or turns off the
+// system clock until a button is pressed
+// https://gbdev.io/pandocs/Reducing_Power_Consumption.html#using-the-stop-instruction
+func (c *Cpu) stop() {
+	c.resetDiv(0)
 
+	key1 := &c.Memory.Io.Regs[0x4D]
+	if c.Model.IsCgb() && GetBit(*key1, 0) {
+		// TODO: the cpu is paused for 2050 M-cycles during the switch
+		c.DoubleSpeed = !c.DoubleSpeed
+		SetBit(key1, 7, c.DoubleSpeed)
+		SetBit(key1, 0, false)
+		return
+	}
+	c.Stop = true
+}
+
+// An illegal opcode hangs the cpu, PC stays on it
+func (c *Cpu) lock() {
+	c.Locked = true
+}
+
+// Wake leaves STOP once one of the selected joypad lines goes low
+func (c *Cpu) Wake() bool {
+	if c.Stop && c.Memory.Joypad.AnyLineLow() {
+		c.Stop = false
+	}
+	return !c.Stop
 }
 
 func (c *Cpu) UpdateTimers(mCyclesThisStep uint64) {
@@ -118,7 +232,7 @@ func (c *Cpu) updateDivReg(mCyclesThisStep uint64) {
 
 	if incr > 0 {
 		div := c.Memory.Io.GetDIV()
-		c.Memory.SetValue(0xFF04, div+uint8(incr)) // Increment DIV
+		c.Memory.Io.Regs[0x04] = div + uint8(incr) // Increment DIV, a cpu write would reset it
 	}
 
 	c.divMCycleCounter = remainingMCycles // Save for next step
@@ -168,17 +282,44 @@ func (c *Cpu) getTimerDivRate(tac uint8) uint64 {
 	return 0 // Invalid
 }
 
+// Step runs one instruction and returns how many M-cycles it took. The rest of the system
+// has already been advanced by then, see Tick.
 func (cpu *Cpu) Step() uint64 {
 
-	instr, _ := cpu.Memory.ReadByteAt(cpu.PC)
+	if cpu.Locked {
+		return 0
+	}
 
 	if !cpu.Halt && cpu.LogFile != nil {
 		cpu.logStep()
 	}
 
-	var ranMCyclesThisStep uint64 = 1 //instr fetch  takes 1 m cycles
+	cpu.mCyclesThisStep = 0
+	instr, _ := cpu.busRead(cpu.PC) //instr fetch  takes 1 m cycles
+
+	// The byte after HALT is read twice: as the opcode here and once more by the
+	// instruction itself (as operand or, for one byte instructions, as the next opcode)
+	if cpu.HaltBug {
+		cpu.HaltBug = false
+		cpu.PC--
+	}
+
 	//decode/Execute
-	ranMCyclesThisStep += cpu.decodeExecute(instr)
+	cpu.op = &OPCODES[instr]
+	cpu.branched = false
+	cpu.op.exec(cpu)
+
+	cycles := uint64(cpu.op.Cycles)
+	if cpu.branched && cpu.op.BranchCycles != 0 {
+		cycles = uint64(cpu.op.BranchCycles)
+	}
+
+	// Internal cycles the instruction did not spend itself, like the extra cycle of a taken jump,
+	// run after its last access. Instructions whose delay comes earlier (PUSH, CALL, RST, RET cc) call Idle themselves.
+	if cycles > cpu.mCyclesThisStep {
+		cpu.Idle(cycles - cpu.mCyclesThisStep)
+	}
+	ranMCyclesThisStep := cpu.mCyclesThisStep
 
 	if cpu.pendingIME {
 		if cpu.setIMETrueIn > 0 {
@@ -191,6 +332,16 @@ func (cpu *Cpu) Step() uint64 {
 	return ranMCyclesThisStep
 }
 
+// NextInstruction disassembles the instruction at PC
+func (cpu *Cpu) NextInstruction() string {
+	read := func(address uint16) uint8 {
+		val, _ := cpu.Memory.ReadByteAt(address)
+		return val
+	}
+	op := Decode(read, cpu.PC)
+	return op.Format(op.Immediate(read, cpu.PC), cpu.PC)
+}
+
 func (cpu *Cpu) logStep() {
 
 	pc1, _ := cpu.Memory.ReadByteAt(cpu.PC)
@@ -0,0 +1,187 @@
+package internal
+
+// 93LC56 opcodes, the two bits after the start bit
+const (
+	EEPROM_OP_EXTENDED uint8 = 0b00 // EWEN, EWDS, WRAL or ERAL, selected by the upper address bits
+	EEPROM_OP_WRITE    uint8 = 0b01
+	EEPROM_OP_READ     uint8 = 0b10
+	EEPROM_OP_ERASE    uint8 = 0b11
+)
+
+// upper two address bits of EEPROM_OP_EXTENDED
+const (
+	EEPROM_EXT_EWDS uint8 = 0b00 // erase/write disable
+	EEPROM_EXT_WRAL uint8 = 0b01 // write all words
+	EEPROM_EXT_ERAL uint8 = 0b10 // erase all words
+	EEPROM_EXT_EWEN uint8 = 0b11 // erase/write enable
+)
+
+const (
+	EEPROM_WORDS        = 128
+	EEPROM_COMMAND_BITS = 10 // start bit excluded: 2 bit opcode and 8 address bits, of which 7 are used
+)
+
+type eepromState uint8
+
+const (
+	eepromIdle    eepromState = iota // waiting for a start bit
+	eepromCommand                    // shifting in opcode and address
+	eepromRead                       // shifting out data
+	eepromWrite                      // shifting in data
+)
+
+// Microchip 93LC56 serial EEPROM in 16 bit mode, the save memory of MBC7 cartridges.
+// The game bit-bangs the chip select, clock and data lines, everything happens on rising clock edges.
+// http://ww1.microchip.com/downloads/en/DeviceDoc/21794F.pdf
+type Eeprom struct {
+	// 128 words, stored little endian
+	data []uint8
+
+	cs, clk, di, do bool
+
+	state        eepromState
+	shift        uint16
+	bits         int
+	address      uint8
+	writeAll     bool
+	writeEnabled bool
+}
+
+func NewEeprom(data []uint8) *Eeprom {
+	return &Eeprom{data: data, do: true}
+}
+
+func (e *Eeprom) word(address uint8) uint16 {
+	i := int(address%EEPROM_WORDS) * 2
+	return uint16(e.data[i]) | uint16(e.data[i+1])<<8
+}
+
+func (e *Eeprom) setWord(address uint8, value uint16) {
+	i := int(address%EEPROM_WORDS) * 2
+	e.data[i] = uint8(value)
+	e.data[i+1] = uint8(value >> 8)
+}
+
+// Pins returns the line states as seen by the game, CS in bit 7, CLK in bit 6, DI in bit 1 and DO in bit 0
+func (e *Eeprom) Pins() uint8 {
+	var value uint8
+	SetBit(&value, 7, e.cs)
+	SetBit(&value, 6, e.clk)
+	SetBit(&value, 1, e.di)
+	SetBit(&value, 0, e.do)
+	return value
+}
+
+func (e *Eeprom) SetPins(value uint8) {
+	cs := GetBit(value, 7)
+	clk := GetBit(value, 6)
+	e.di = GetBit(value, 1)
+
+	// dropping chip select aborts the current command
+	if !cs {
+		e.state = eepromIdle
+		e.do = true
+	}
+	rising := cs && clk && !e.clk
+	e.cs, e.clk = cs, clk
+	if rising {
+		e.clock()
+	}
+}
+
+func (e *Eeprom) clock() {
+	var bit uint16
+	if e.di {
+		bit = 1
+	}
+
+	switch e.state {
+	case eepromIdle:
+		if e.di {
+			e.state = eepromCommand
+			e.shift, e.bits = 0, 0
+		}
+
+	case eepromCommand:
+		e.shift = e.shift<<1 | bit
+		e.bits++
+		if e.bits == EEPROM_COMMAND_BITS {
+			e.execute(uint8(e.shift>>8), uint8(e.shift))
+		}
+
+	case eepromRead:
+		// data is shifted out msb first, continuing with the next word until CS goes low
+		if e.bits == 16 {
+			e.address++
+			e.shift, e.bits = e.word(e.address), 0
+		}
+		e.do = e.shift&0x8000 != 0
+		e.shift <<= 1
+		e.bits++
+
+	case eepromWrite:
+		e.shift = e.shift<<1 | bit
+		e.bits++
+		if e.bits < 16 {
+			return
+		}
+		if e.writeEnabled {
+			if e.writeAll {
+				for address := range uint8(EEPROM_WORDS) {
+					e.setWord(address, e.shift)
+				}
+			} else {
+				e.setWord(e.address, e.shift)
+			}
+		}
+		e.finish()
+	}
+}
+
+func (e *Eeprom) execute(opcode uint8, address uint8) {
+	e.address = address & 0x7F
+	e.shift, e.bits = 0, 0
+
+	switch opcode {
+	case EEPROM_OP_READ:
+		// a dummy 0 is output before the data
+		e.do = false
+		e.shift = e.word(e.address)
+		e.state = eepromRead
+
+	case EEPROM_OP_WRITE:
+		e.writeAll = false
+		e.state = eepromWrite
+
+	case EEPROM_OP_ERASE:
+		if e.writeEnabled {
+			e.setWord(e.address, 0xFFFF)
+		}
+		e.finish()
+
+	case EEPROM_OP_EXTENDED:
+		switch address >> 6 {
+		case EEPROM_EXT_EWEN:
+			e.writeEnabled = true
+		case EEPROM_EXT_EWDS:
+			e.writeEnabled = false
+		case EEPROM_EXT_ERAL:
+			if e.writeEnabled {
+				for i := range e.data {
+					e.data[i] = 0xFF
+				}
+			}
+		case EEPROM_EXT_WRAL:
+			e.writeAll = true
+			e.state = eepromWrite
+			return
+		}
+		e.finish()
+	}
+}
+
+// programming is instant, DO reports ready right away
+func (e *Eeprom) finish() {
+	e.state = eepromIdle
+	e.do = true
+}
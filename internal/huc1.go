@@ -0,0 +1,45 @@
+package internal
+
+// https://gbdev.io/pandocs/HuC1.html
+type Huc1 struct {
+	mbcBase
+	infraredReg
+
+	// 0x0000-0x1FFF: 0x0E maps the IR register to 0xA000-0xBFFF, anything else the RAM
+	irMode bool
+}
+
+func NewHuc1(rom *Rom) *Huc1 {
+	m := &Huc1{mbcBase: newMbcBase(rom)}
+	// there is no separate RAM enable, the RAM is accessible whenever IR mode is off
+	m.ramEnabled = true
+	return m
+}
+
+func (m *Huc1) WriteRom(address uint16, value uint8) {
+	switch {
+	case address < 0x2000:
+		m.irMode = value&0x0F == 0x0E
+
+	case address < 0x4000:
+		m.romBankN = int(value & 0x3F)
+
+	case address < 0x6000:
+		m.ramBank = int(value & 0x03)
+	}
+}
+
+func (m *Huc1) ReadRam(address uint16) uint8 {
+	if m.irMode {
+		return m.infraredReg.read()
+	}
+	return m.mbcBase.ReadRam(address)
+}
+
+func (m *Huc1) WriteRam(address uint16, value uint8) {
+	if m.irMode {
+		m.infraredReg.write(value)
+		return
+	}
+	m.mbcBase.WriteRam(address, value)
+}
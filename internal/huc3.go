@@ -0,0 +1,212 @@
+package internal
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// HuC3 modes, selected by writing to 0x0000-0x1FFF
+const (
+	HUC3_MODE_RAM_READ  uint8 = 0x0 // RAM can be read but not written
+	HUC3_MODE_RAM       uint8 = 0xA
+	HUC3_MODE_COMMAND   uint8 = 0xB // write an RTC command and its argument
+	HUC3_MODE_RESPONSE  uint8 = 0xC // read the result of the last command
+	HUC3_MODE_SEMAPHORE uint8 = 0xD // bit 0 set: the RTC is ready for the next command
+	HUC3_MODE_IR        uint8 = 0xE
+)
+
+// RTC commands, bits 4-6 of a write in HUC3_MODE_COMMAND. Bits 0-3 are the argument.
+const (
+	HUC3_CMD_READ      uint8 = 0x1 // read the nibble at the address and increment it
+	HUC3_CMD_WRITE     uint8 = 0x3 // write the argument to the address and increment it
+	HUC3_CMD_ADDR_LOW  uint8 = 0x4
+	HUC3_CMD_ADDR_HIGH uint8 = 0x5
+	HUC3_CMD_EXTENDED  uint8 = 0x6
+)
+
+// Arguments of HUC3_CMD_EXTENDED
+const (
+	HUC3_EXT_LOAD_TIME  uint8 = 0x0 // copy the clock to memory 0x00-0x05
+	HUC3_EXT_STORE_TIME uint8 = 0x1 // set the clock from memory 0x00-0x05
+	HUC3_EXT_STATUS     uint8 = 0x2 // responds with 1
+	HUC3_EXT_TONE       uint8 = 0xE // play the tone selected at HUC3_TONE_ADDR
+)
+
+// Nibble addresses in the RTC memory. The minute of the day and the day counter
+// are 12 bit values stored lowest nibble first.
+const (
+	HUC3_MINUTES_ADDR = 0x00
+	HUC3_DAYS_ADDR    = 0x03
+	HUC3_TONE_ADDR    = 0x26
+)
+
+const (
+	HUC3_MINUTES_PER_DAY    = 24 * 60
+	HUC3_MCYCLES_PER_MINUTE = 60 * RTC_MCYCLES_PER_SECOND
+	HUC3_FOOTER_SIZE        = 12 // uint16 minutes, uint16 days, uint64 unix timestamp, little endian
+)
+
+// https://gbdev.io/pandocs/HuC3.html
+type Huc3 struct {
+	mbcBase
+	infraredReg
+
+	mode uint8
+
+	// RTC chip, driven through the command interface
+	memory   [0x100]uint8 // one nibble per address
+	address  uint8
+	response uint8  // last command in the upper, its result in the lower nibble
+	Minutes  uint16 // minute of the day, 0-1439
+	Days     uint16 // 12 bit day counter
+	mCycles  uint64 // sub-minute counter
+	onTone   func(tone uint8)
+
+	clockWrites uint64 // times the game set the clock, see SaveState
+}
+
+func NewHuc3(rom *Rom) *Huc3 {
+	return &Huc3{mbcBase: newMbcBase(rom)}
+}
+
+// The tone generator drives the cartridge speaker, the callback gets the selected tone
+func (m *Huc3) SetToneCallback(callback func(tone uint8)) {
+	m.onTone = callback
+}
+
+func (m *Huc3) Tick(mCycles uint64) {
+	m.mCycles += mCycles
+	if m.mCycles >= HUC3_MCYCLES_PER_MINUTE {
+		m.advanceMinutes(m.mCycles / HUC3_MCYCLES_PER_MINUTE)
+		m.mCycles %= HUC3_MCYCLES_PER_MINUTE
+	}
+}
+
+func (m *Huc3) advanceMinutes(minutes uint64) {
+	total := uint64(m.Minutes) + minutes
+	m.Minutes = uint16(total % HUC3_MINUTES_PER_DAY)
+	m.Days = uint16((uint64(m.Days) + total/HUC3_MINUTES_PER_DAY) & 0xFFF)
+}
+
+func (m *Huc3) WriteRom(address uint16, value uint8) {
+	switch {
+	case address < 0x2000:
+		m.mode = value & 0x0F
+		m.ramEnabled = m.mode == HUC3_MODE_RAM
+
+	case address < 0x4000:
+		m.romBankN = int(value & 0x7F)
+
+	case address < 0x6000:
+		m.ramBank = int(value & 0x03)
+	}
+}
+
+func (m *Huc3) ReadRam(address uint16) uint8 {
+	switch m.mode {
+	case HUC3_MODE_RAM, HUC3_MODE_RAM_READ:
+		if len(m.ram) == 0 {
+			return 0xFF
+		}
+		return m.ram[m.ramOffset(address)]
+	case HUC3_MODE_RESPONSE:
+		return m.response
+	case HUC3_MODE_SEMAPHORE:
+		// commands finish instantly
+		return 0x01
+	case HUC3_MODE_IR:
+		return m.infraredReg.read()
+	}
+	return 0xFF
+}
+
+func (m *Huc3) WriteRam(address uint16, value uint8) {
+	switch m.mode {
+	case HUC3_MODE_RAM:
+		m.mbcBase.WriteRam(address, value)
+	case HUC3_MODE_COMMAND:
+		m.command(value>>4&0x07, value&0x0F)
+	case HUC3_MODE_IR:
+		m.infraredReg.write(value)
+	}
+}
+
+func (m *Huc3) command(cmd uint8, arg uint8) {
+	result := arg
+	switch cmd {
+	case HUC3_CMD_READ:
+		result = m.memory[m.address]
+		m.address++
+	case HUC3_CMD_WRITE:
+		m.memory[m.address] = arg
+		m.address++
+	case HUC3_CMD_ADDR_LOW:
+		m.address = m.address&0xF0 | arg
+	case HUC3_CMD_ADDR_HIGH:
+		m.address = m.address&0x0F | arg<<4
+	case HUC3_CMD_EXTENDED:
+		result = m.extendedCommand(arg)
+	}
+	m.response = cmd<<4 | result&0x0F
+}
+
+func (m *Huc3) extendedCommand(arg uint8) uint8 {
+	switch arg {
+	case HUC3_EXT_LOAD_TIME:
+		m.storeNibbles(HUC3_MINUTES_ADDR, m.Minutes)
+		m.storeNibbles(HUC3_DAYS_ADDR, m.Days)
+	case HUC3_EXT_STORE_TIME:
+		m.Minutes = m.loadNibbles(HUC3_MINUTES_ADDR) % HUC3_MINUTES_PER_DAY
+		m.Days = m.loadNibbles(HUC3_DAYS_ADDR)
+		m.mCycles = 0
+		m.clockWrites++
+	case HUC3_EXT_STATUS:
+		return 0x1
+	case HUC3_EXT_TONE:
+		if m.onTone != nil {
+			m.onTone(m.memory[HUC3_TONE_ADDR] & 0x0F)
+		}
+	}
+	return arg
+}
+
+func (m *Huc3) storeNibbles(address uint8, value uint16) {
+	for i := range uint8(3) {
+		m.memory[address+i] = uint8(value>>(4*i)) & 0x0F
+	}
+}
+
+func (m *Huc3) loadNibbles(address uint8) uint16 {
+	var value uint16
+	for i := range uint8(3) {
+		value |= uint16(m.memory[address+i]&0x0F) << (4 * i)
+	}
+	return value
+}
+
+// The clock is stored behind the RAM in the .sav file and catches up with the real time on load
+func (m *Huc3) SaveData(now time.Time) []uint8 {
+	footer := make([]uint8, HUC3_FOOTER_SIZE)
+	binary.LittleEndian.PutUint16(footer[0:], m.Minutes)
+	binary.LittleEndian.PutUint16(footer[2:], m.Days)
+	binary.LittleEndian.PutUint64(footer[4:], uint64(now.Unix()))
+	return append(m.mbcBase.SaveData(now), footer...)
+}
+
+func (m *Huc3) saveState() []uint8 {
+	return clockSaveState(m.ram, m.clockWrites)
+}
+
+func (m *Huc3) LoadSaveData(data []uint8) {
+	m.mbcBase.LoadSaveData(data)
+	if len(data) != len(m.ram)+HUC3_FOOTER_SIZE {
+		return
+	}
+	footer := data[len(m.ram):]
+	m.Minutes = binary.LittleEndian.Uint16(footer[0:]) % HUC3_MINUTES_PER_DAY
+	m.Days = binary.LittleEndian.Uint16(footer[2:]) & 0xFFF
+	savedAt := int64(binary.LittleEndian.Uint64(footer[4:]))
+	if elapsed := time.Now().Unix() - savedAt; elapsed > 0 {
+		m.advanceMinutes(uint64(elapsed) / 60)
+	}
+}
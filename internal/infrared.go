@@ -0,0 +1,60 @@
+package internal
+
+import "sync/atomic"
+
+// Infrared transceiver of a cartridge (HuC1, HuC3).
+// The cartridge switches its LED with SetLed and polls ReceivingLight for incoming light.
+type InfraredPort interface {
+	SetLed(on bool)
+	ReceivingLight() bool
+}
+
+type InfraredMbc interface {
+	SetInfraredPort(port InfraredPort) // nil disconnects the port
+}
+
+// One end of an InfraredLink, its LED lights up the receiver of the other end
+type InfraredEnd struct {
+	led   atomic.Bool
+	other *InfraredEnd
+}
+
+// NewInfraredLink returns two ports facing each other, so two emulators
+// (possibly running in different goroutines) can talk over infrared.
+func NewInfraredLink() (*InfraredEnd, *InfraredEnd) {
+	a, b := &InfraredEnd{}, &InfraredEnd{}
+	a.other, b.other = b, a
+	return a, b
+}
+
+func (p *InfraredEnd) SetLed(on bool) {
+	p.led.Store(on)
+}
+
+func (p *InfraredEnd) ReceivingLight() bool {
+	return p.other.led.Load()
+}
+
+// Shared infrared register of the Hudson mappers, mapped to 0xA000-0xBFFF in IR mode
+type infraredReg struct {
+	port InfraredPort
+}
+
+func (r *infraredReg) SetInfraredPort(port InfraredPort) {
+	r.port = port
+}
+
+// bit 0 is set while light is received, the rest reads as 0xC0
+func (r *infraredReg) read() uint8 {
+	if r.port != nil && r.port.ReceivingLight() {
+		return 0xC1
+	}
+	return 0xC0
+}
+
+// bit 0 switches the LED
+func (r *infraredReg) write(value uint8) {
+	if r.port != nil {
+		r.port.SetLed(GetBit(value, 0))
+	}
+}
@@ -34,35 +34,161 @@ const (
 
 type Ioregs struct {
 	Regs [0x80]uint8
-}
 
-func (i *Ioregs) GetJOYP() uint8 {
-	return i.Regs[0x00]
+	Model Model // decides which registers exist
+
+	readHooks  [0x80]IoReadHook
+	writeHooks [0x80]IoWriteHook
+}
+
+// Hooks let the subsystem that owns a register (timer, PPU, serial, joypad, DMA) handle
+// accesses from the cpu side. A read hook returns the register without its unused bits,
+// a write hook gets the value as the cpu wrote it and stores what it needs with Store.
+type IoReadHook func() uint8
+type IoWriteHook func(value uint8)
+
+// OnRead replaces the read handling of a register, add is relative to 0xFF00
+func (i *Ioregs) OnRead(add uint16, hook IoReadHook) {
+	i.readHooks[add] = hook
+}
+
+// OnWrite replaces the write handling of a register, add is relative to 0xFF00
+func (i *Ioregs) OnWrite(add uint16, hook IoWriteHook) {
+	i.writeHooks[add] = hook
+}
+
+// How a register looks to the cpu
+type ioRegMask struct {
+	mapped   bool
+	cgbOnly  bool
+	readOnes uint8 // unused and write-only bits, they read as 1
+	writable uint8 // bits the cpu can change, the rest is read-only or unused
+}
+
+// Registers without an entry are unmapped: they read 0xFF and ignore writes.
+// https://gbdev.io/pandocs/Hardware_Reg_List.html
+var ioRegMasks = [0x80]ioRegMask{
+	0x00: {mapped: true, readOnes: 0xC0, writable: 0x30}, // P1, bits 0-3 come from the buttons
+	0x01: {mapped: true, readOnes: 0x00, writable: 0xFF}, // SB
+	0x02: {mapped: true, readOnes: 0x7E, writable: 0x81}, // SC, see ioRegMaskCgbSC
+	0x04: {mapped: true, readOnes: 0x00, writable: 0xFF}, // DIV, any write resets it
+	0x05: {mapped: true, readOnes: 0x00, writable: 0xFF}, // TIMA
+	0x06: {mapped: true, readOnes: 0x00, writable: 0xFF}, // TMA
+	0x07: {mapped: true, readOnes: 0xF8, writable: 0x07}, // TAC
+	0x0F: {mapped: true, readOnes: 0xE0, writable: 0x1F}, // IF
+
+	0x10: {mapped: true, readOnes: 0x80, writable: 0x7F}, // NR10
+	0x11: {mapped: true, readOnes: 0x3F, writable: 0xFF}, // NR11, the length is write-only
+	0x12: {mapped: true, readOnes: 0x00, writable: 0xFF}, // NR12
+	0x13: {mapped: true, readOnes: 0xFF, writable: 0xFF}, // NR13, write-only
+	0x14: {mapped: true, readOnes: 0xBF, writable: 0xC7}, // NR14, only the length enable can be read
+	0x16: {mapped: true, readOnes: 0x3F, writable: 0xFF}, // NR21
+	0x17: {mapped: true, readOnes: 0x00, writable: 0xFF}, // NR22
+	0x18: {mapped: true, readOnes: 0xFF, writable: 0xFF}, // NR23
+	0x19: {mapped: true, readOnes: 0xBF, writable: 0xC7}, // NR24
+	0x1A: {mapped: true, readOnes: 0x7F, writable: 0x80}, // NR30
+	0x1B: {mapped: true, readOnes: 0xFF, writable: 0xFF}, // NR31
+	0x1C: {mapped: true, readOnes: 0x9F, writable: 0x60}, // NR32
+	0x1D: {mapped: true, readOnes: 0xFF, writable: 0xFF}, // NR33
+	0x1E: {mapped: true, readOnes: 0xBF, writable: 0xC7}, // NR34
+	0x20: {mapped: true, readOnes: 0xFF, writable: 0x3F}, // NR41
+	0x21: {mapped: true, readOnes: 0x00, writable: 0xFF}, // NR42
+	0x22: {mapped: true, readOnes: 0x00, writable: 0xFF}, // NR43
+	0x23: {mapped: true, readOnes: 0xBF, writable: 0xC0}, // NR44
+	0x24: {mapped: true, readOnes: 0x00, writable: 0xFF}, // NR50
+	0x25: {mapped: true, readOnes: 0x00, writable: 0xFF}, // NR51
+	0x26: {mapped: true, readOnes: 0x70, writable: 0x80}, // NR52, bits 0-3 are the channel status
+
+	// Wave RAM
+	0x30: {mapped: true, writable: 0xFF}, 0x31: {mapped: true, writable: 0xFF},
+	0x32: {mapped: true, writable: 0xFF}, 0x33: {mapped: true, writable: 0xFF},
+	0x34: {mapped: true, writable: 0xFF}, 0x35: {mapped: true, writable: 0xFF},
+	0x36: {mapped: true, writable: 0xFF}, 0x37: {mapped: true, writable: 0xFF},
+	0x38: {mapped: true, writable: 0xFF}, 0x39: {mapped: true, writable: 0xFF},
+	0x3A: {mapped: true, writable: 0xFF}, 0x3B: {mapped: true, writable: 0xFF},
+	0x3C: {mapped: true, writable: 0xFF}, 0x3D: {mapped: true, writable: 0xFF},
+	0x3E: {mapped: true, writable: 0xFF}, 0x3F: {mapped: true, writable: 0xFF},
+
+	0x40: {mapped: true, readOnes: 0x00, writable: 0xFF}, // LCDC
+	0x41: {mapped: true, readOnes: 0x80, writable: 0x78}, // STAT, mode and LY=LYC are read-only
+	0x42: {mapped: true, readOnes: 0x00, writable: 0xFF}, // SCY
+	0x43: {mapped: true, readOnes: 0x00, writable: 0xFF}, // SCX
+	0x44: {mapped: true, readOnes: 0x00, writable: 0x00}, // LY
+	0x45: {mapped: true, readOnes: 0x00, writable: 0xFF}, // LYC
+	0x46: {mapped: true, readOnes: 0x00, writable: 0xFF}, // DMA
+	0x47: {mapped: true, readOnes: 0x00, writable: 0xFF}, // BGP
+	0x48: {mapped: true, readOnes: 0x00, writable: 0xFF}, // OBP0
+	0x49: {mapped: true, readOnes: 0x00, writable: 0xFF}, // OBP1
+	0x4A: {mapped: true, readOnes: 0x00, writable: 0xFF}, // WY
+	0x4B: {mapped: true, readOnes: 0x00, writable: 0xFF}, // WX
+	0x50: {mapped: true, readOnes: 0xFF, writable: 0xFF}, // BANK, boot rom disable
+
+	0x4D: {mapped: true, cgbOnly: true, readOnes: 0x7E, writable: 0x01}, // KEY1
+	0x4F: {mapped: true, cgbOnly: true, readOnes: 0xFE, writable: 0x01}, // VBK
+	0x51: {mapped: true, cgbOnly: true, readOnes: 0xFF, writable: 0xFF}, // HDMA1, write-only
+	0x52: {mapped: true, cgbOnly: true, readOnes: 0xFF, writable: 0xFF}, // HDMA2
+	0x53: {mapped: true, cgbOnly: true, readOnes: 0xFF, writable: 0xFF}, // HDMA3
+	0x54: {mapped: true, cgbOnly: true, readOnes: 0xFF, writable: 0xFF}, // HDMA4
+	0x55: {mapped: true, cgbOnly: true, readOnes: 0x00, writable: 0xFF}, // HDMA5
+	0x56: {mapped: true, cgbOnly: true, readOnes: 0x3C, writable: 0xC1}, // RP
+	0x68: {mapped: true, cgbOnly: true, readOnes: 0x40, writable: 0xBF}, // BCPS
+	0x69: {mapped: true, cgbOnly: true, readOnes: 0x00, writable: 0xFF}, // BCPD
+	0x6A: {mapped: true, cgbOnly: true, readOnes: 0x40, writable: 0xBF}, // OCPS
+	0x6B: {mapped: true, cgbOnly: true, readOnes: 0x00, writable: 0xFF}, // OCPD
+	0x6C: {mapped: true, cgbOnly: true, readOnes: 0xFE, writable: 0x01}, // OPRI
+	0x70: {mapped: true, cgbOnly: true, readOnes: 0xF8, writable: 0x07}, // SVBK
+	0x72: {mapped: true, cgbOnly: true, readOnes: 0x00, writable: 0xFF}, // undocumented
+	0x73: {mapped: true, cgbOnly: true, readOnes: 0x00, writable: 0xFF}, // undocumented
+	0x74: {mapped: true, cgbOnly: true, readOnes: 0x00, writable: 0xFF}, // undocumented
+	0x75: {mapped: true, cgbOnly: true, readOnes: 0x8F, writable: 0x70}, // undocumented
+	0x76: {mapped: true, cgbOnly: true, readOnes: 0x00, writable: 0x00}, // PCM12
+	0x77: {mapped: true, cgbOnly: true, readOnes: 0x00, writable: 0x00}, // PCM34
+}
+
+// The CGB adds the clock speed bit to SC
+var ioRegMaskCgbSC = ioRegMask{mapped: true, readOnes: 0x7C, writable: 0x83}
+
+func (i *Ioregs) mask(add uint16) ioRegMask {
+	m := ioRegMasks[add]
+	if add == 0x02 && i.Model.IsCgb() {
+		return ioRegMaskCgbSC
+	}
+	if m.cgbOnly && !i.Model.IsCgb() {
+		return ioRegMask{}
+	}
+	return m
 }
 
-func (i *Ioregs) HandleStat(value uint8) {
-	currentSTAT := i.Regs[0x41]
-
-	// 1. Keep the current read-only bits (0-2)
-	readOnlyBits := currentSTAT & 0x07
-
-	// 2. Take the new interrupt bits from the 'value' (3-6)
-	writableBits := value & 0x78
-
-	// 3. Set bit 7 to 1 (standard for DMG hardware)
-	const bit7 = 0x80
-
-	// Combine them back together
-	i.Regs[0x41] = bit7 | writableBits | readOnlyBits
+// GetAtAddress returns a register as the cpu reads it
+func (i *Ioregs) GetAtAddress(add uint16) uint8 {
+	m := i.mask(add)
+	if !m.mapped {
+		return 0xFF
+	}
+	if hook := i.readHooks[add]; hook != nil {
+		return hook() | m.readOnes
+	}
+	return i.Regs[add] | m.readOnes
 }
-func (i *Ioregs) SetAtAdress(add uint16, val uint8) {
 
-	if add == 0x41 {
-		i.HandleStat(val)
+// SetAtAdress writes a register like the cpu does, everything going through Mmap.SetValue ends up here
+// so the owner of the register gets to apply its side effects
+func (i *Ioregs) SetAtAdress(add uint16, val uint8) {
+	m := i.mask(add)
+	if !m.mapped {
 		return
 	}
+	if hook := i.writeHooks[add]; hook != nil {
+		hook(val)
+		return
+	}
+	i.Store(add, val)
+}
 
-	i.Regs[add] = val
+// Store changes the writable bits of a register, read-only bits keep their value
+func (i *Ioregs) Store(add uint16, val uint8) {
+	m := i.mask(add)
+	i.Regs[add] = i.Regs[add]&^m.writable | val&m.writable
 }
 
 func (i *Ioregs) SetJOYP(value uint8) {
@@ -304,9 +430,9 @@ func (i *Ioregs) SetSTAT(value uint8) {
 func (i *Ioregs) SetSTATBit(bit StatFlags, value bool) {
 	stat := i.GetSTAT()
 	if value {
-		stat |= uint8(bit)
+		stat |= 1 << bit
 	} else {
-		stat &= ^uint8(bit)
+		stat &^= 1 << bit
 	}
 	i.SetSTAT(stat)
 }
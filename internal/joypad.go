@@ -0,0 +1,61 @@
+package internal
+
+type JoypadButton uint8
+
+// Bits of Joypad.Pressed, the low nibble is the d-pad
+const (
+	BUTTON_RIGHT JoypadButton = 1 << iota
+	BUTTON_LEFT
+	BUTTON_UP
+	BUTTON_DOWN
+	BUTTON_A
+	BUTTON_B
+	BUTTON_SELECT
+	BUTTON_START
+)
+
+// Joypad owns P1 (0xFF00). The game selects the d-pad and/or the buttons with bits 4 and 5
+// and reads the selected ones from bits 0-3, where 0 means pressed.
+// https://gbdev.io/pandocs/Joypad_Input.html
+type Joypad struct {
+	Pressed JoypadButton
+
+	io *Ioregs
+}
+
+func (j *Joypad) connectIo(io *Ioregs) {
+	j.io = io
+	io.OnRead(0x00, j.readP1)
+}
+
+func (j *Joypad) readP1() uint8 {
+	sel := j.io.Regs[0x00] & 0x30
+	return sel | j.lines(sel)
+}
+
+// lines returns the low nibble of P1 for the given selection
+func (j *Joypad) lines(sel uint8) uint8 {
+	lines := uint8(0x0F)
+	if sel&0x10 == 0 {
+		lines &^= uint8(j.Pressed) & 0x0F
+	}
+	if sel&0x20 == 0 {
+		lines &^= uint8(j.Pressed) >> 4
+	}
+	return lines
+}
+
+// AnyLineLow reports whether a pressed button is selected by P1
+func (j *Joypad) AnyLineLow() bool {
+	return j.lines(j.io.Regs[0x00]&0x30) != 0x0F
+}
+
+// SetPressed updates the held buttons, a selected line going from high to low requests the joypad interrupt
+func (j *Joypad) SetPressed(pressed JoypadButton) {
+	sel := j.io.Regs[0x00] & 0x30
+	before := j.lines(sel)
+	j.Pressed = pressed
+	if before&^j.lines(sel) != 0 {
+		j.io.SetInterruptFlagBit(JOYPAD, true)
+	}
+}
@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Bank controller hardware. Usually this follows from the cartridge type in the header,
+// but unlicensed and multicart boards often carry a header that says something else.
+type Mapper uint8
+
+const (
+	MAPPER_UNKNOWN Mapper = iota
+	MAPPER_NONE
+	MAPPER_MBC1
+	MAPPER_MBC1M // MBC1 multicart wiring, see Mbc1
+	MAPPER_MBC2
+	MAPPER_MBC3
+	MAPPER_MBC5
+	MAPPER_MBC7
+	MAPPER_MMM01
+	MAPPER_WISDOM_TREE
+	MAPPER_SACHEN_MMC1
+	MAPPER_CAMERA
+	MAPPER_HUC1
+	MAPPER_HUC3
+)
+
+var mapperNames = map[Mapper]string{
+	MAPPER_UNKNOWN:     "unknown",
+	MAPPER_NONE:        "none",
+	MAPPER_MBC1:        "mbc1",
+	MAPPER_MBC1M:       "mbc1m",
+	MAPPER_MBC2:        "mbc2",
+	MAPPER_MBC3:        "mbc3",
+	MAPPER_MBC5:        "mbc5",
+	MAPPER_MBC7:        "mbc7",
+	MAPPER_MMM01:       "mmm01",
+	MAPPER_WISDOM_TREE: "wisdomtree",
+	MAPPER_SACHEN_MMC1: "sachen",
+	MAPPER_CAMERA:      "camera",
+	MAPPER_HUC1:        "huc1",
+	MAPPER_HUC3:        "huc3",
+}
+
+func (m Mapper) String() string {
+	if name, ok := mapperNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("Mapper(%d)", uint8(m))
+}
+
+var ErrUnknownMapper = errors.New("unknown mapper")
+
+func ParseMapper(name string) (Mapper, error) {
+	for m, n := range mapperNames {
+		if n == strings.ToLower(name) && m != MAPPER_UNKNOWN {
+			return m, nil
+		}
+	}
+	return MAPPER_UNKNOWN, fmt.Errorf("%w %q", ErrUnknownMapper, name)
+}
+
+func (c CartridgeType) Mapper() Mapper {
+	switch c {
+	case CART_ROM_ONLY, CART_ROM_RAM, CART_ROM_RAM_BATTERY:
+		return MAPPER_NONE
+	case CART_MBC1, CART_MBC1_RAM, CART_MBC1_RAM_BATTERY:
+		return MAPPER_MBC1
+	case CART_MBC2, CART_MBC2_BATTERY:
+		return MAPPER_MBC2
+	case CART_MMM01, CART_MMM01_RAM, CART_MMM01_RAM_BATTERY:
+		return MAPPER_MMM01
+	case CART_MBC3, CART_MBC3_RAM, CART_MBC3_RAM_BATTERY, CART_MBC3_TIMER_BATTERY, CART_MBC3_TIMER_RAM_BATTERY:
+		return MAPPER_MBC3
+	case CART_MBC5, CART_MBC5_RAM, CART_MBC5_RAM_BATTERY, CART_MBC5_RUMBLE, CART_MBC5_RUMBLE_RAM, CART_MBC5_RUMBLE_RAM_BATTERY:
+		return MAPPER_MBC5
+	case CART_MBC7_SENSOR_RUMBLE:
+		return MAPPER_MBC7
+	case CART_POCKET_CAMERA:
+		return MAPPER_CAMERA
+	case CART_HUC1_RAM_BATTERY:
+		return MAPPER_HUC1
+	case CART_HUC3:
+		return MAPPER_HUC3
+	}
+	return MAPPER_UNKNOWN
+}
+
+// DetectMapper looks at the quirk database first, then at the rom contents and finally at the header
+func DetectMapper(rom *Rom) Mapper {
+	h := &rom.Header
+	if m := rom.Quirk().Mapper; m != MAPPER_UNKNOWN {
+		return m
+	}
+
+	data := rom.GetData()
+	switch {
+	case mmm01Header(data) != nil:
+		return MAPPER_MMM01
+	case isWisdomTree(data):
+		return MAPPER_WISDOM_TREE
+	case isSachenMmc1(data):
+		return MAPPER_SACHEN_MMC1
+	}
+
+	m := h.CartridgeType.Mapper()
+	if m == MAPPER_MBC1 && isMbc1Multicart(padRom(data)) {
+		return MAPPER_MBC1M
+	}
+	return m
+}
+
+// Wisdom Tree games say ROM ONLY in the header, their only common trait is the publisher name in bank 0
+func isWisdomTree(data []byte) bool {
+	if len(data) <= 2*ROM_BANK_SIZE || CartridgeType(data[HEADER_CARTRIDGE_TYPE]) != CART_ROM_ONLY {
+		return false
+	}
+	bank0 := data[:ROM_BANK_SIZE]
+	return bytes.Contains(bank0, []byte("WISDOM TREE")) || bytes.Contains(bank0, []byte("WISDOM\x00TREE"))
+}
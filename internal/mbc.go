@@ -0,0 +1,252 @@
+package internal
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var ErrUnsupportedCartridge = errors.New("unsupported cartridge type")
+
+// Memory bank controller of the cartridge.
+// It owns the ROM (0x0000-0x7FFF) and the external RAM (0xA000-0xBFFF).
+type Mbc interface {
+	ReadRom(address uint16) uint8
+	WriteRom(address uint16, value uint8)
+	ReadRam(address uint16) uint8
+	WriteRam(address uint16, value uint8)
+
+	// Views of the currently mapped banks, used by the debugger
+	GetBank0() []uint8
+	GetBankN() []uint8
+	GetRamBank() []uint8
+
+	// The whole external RAM
+	GetRam() []uint8
+}
+
+// Cartridges with hardware that runs alongside the CPU, like the MBC3 real-time clock
+type ClockedMbc interface {
+	Tick(mCycles uint64)
+}
+
+// Banks currently mapped to 0x0000-0x3FFF and 0x4000-0x7FFF, used to show banked addresses
+type BankedMbc interface {
+	RomBank0() int
+	RomBankN() int
+}
+
+// Cartridges that show the boot rom something else than the game, see SachenMmc1
+type bootMbc interface {
+	readRomDuringBoot(address uint16) uint8
+}
+
+// Cartridges with a clock in their save data, see SaveState
+type clockSaveMbc interface {
+	saveState() []uint8
+}
+
+type RtcMbc interface {
+	GetRtc() *Rtc // nil if the cartridge has no clock
+}
+
+// Cartridges whose RAM is kept alive by a battery, the save data is what ends up in the .sav file.
+// Carts with a clock put now in it, so the clock can catch up on load.
+type BatteryMbc interface {
+	SaveData(now time.Time) []uint8
+	LoadSaveData(data []uint8)
+}
+
+type RumbleMbc interface {
+	SetRumbleCallback(callback func(on bool))
+}
+
+type ToneMbc interface {
+	SetToneCallback(callback func(tone uint8))
+}
+
+func NewMbc(rom *Rom) (Mbc, error) {
+	switch DetectMapper(rom) {
+	case MAPPER_NONE:
+		return NewNoMbc(rom), nil
+	case MAPPER_MBC1:
+		return NewMbc1(rom), nil
+	case MAPPER_MBC1M:
+		return newMbc1(rom, true), nil
+	case MAPPER_MBC2:
+		return NewMbc2(rom), nil
+	case MAPPER_MBC3:
+		return NewMbc3(rom), nil
+	case MAPPER_MBC5:
+		return NewMbc5(rom), nil
+	case MAPPER_MBC7:
+		return NewMbc7(rom), nil
+	case MAPPER_MMM01:
+		return NewMmm01(rom), nil
+	case MAPPER_WISDOM_TREE:
+		return NewWisdomTree(rom), nil
+	case MAPPER_SACHEN_MMC1:
+		return NewSachenMmc1(rom), nil
+	case MAPPER_CAMERA:
+		return NewCamera(rom), nil
+	case MAPPER_HUC1:
+		return NewHuc1(rom), nil
+	case MAPPER_HUC3:
+		return NewHuc3(rom), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedCartridge, rom.Header.CartridgeType)
+}
+
+// Shared state of all bank controllers. Each controller only has to keep
+// romBank0, romBankN and ramBank up to date, reads and writes are handled here.
+type mbcBase struct {
+	rom []uint8
+	ram []uint8
+
+	romBank0   int // bank mapped to 0x0000-0x3FFF
+	romBankN   int // bank mapped to 0x4000-0x7FFF
+	ramBank    int // bank mapped to 0xA000-0xBFFF
+	ramEnabled bool
+}
+
+func newMbcBase(rom *Rom) mbcBase {
+	return mbcBase{
+		rom:      padRom(rom.GetData()),
+		ram:      make([]uint8, cartRamSize(&rom.Header)),
+		romBank0: 0,
+		romBankN: 1,
+	}
+}
+
+// padRom returns the rom data grown to a whole number of (at least two) 16 KiB banks,
+// so bank lookups never run past the end of an odd sized dump.
+func padRom(data []uint8) []uint8 {
+	size := max(len(data), 2*ROM_BANK_SIZE)
+	if rest := size % ROM_BANK_SIZE; rest != 0 {
+		size += ROM_BANK_SIZE - rest
+	}
+	if size == len(data) {
+		return data
+	}
+	padded := make([]uint8, size)
+	copy(padded, data)
+	for i := len(data); i < size; i++ {
+		padded[i] = 0xFF
+	}
+	return padded
+}
+
+// Some cartridges declare RAM in their type but report a RAM size of 0 in the header,
+// most emulators map 8 KiB in that case.
+func cartRamSize(h *Header) int {
+	size, _ := h.RamSize()
+	if size == 0 && h.CartridgeType.HasRam() {
+		return RAM_BANK_SIZE
+	}
+	return size
+}
+
+func (b *mbcBase) romBanks() int {
+	return len(b.rom) / ROM_BANK_SIZE
+}
+
+// bank numbers wrap around the actual rom size, like the unconnected address lines on a real cartridge
+func (b *mbcBase) romOffset(bank int) int {
+	return (bank % b.romBanks()) * ROM_BANK_SIZE
+}
+
+func (b *mbcBase) ramOffset(address uint16) int {
+	return (b.ramBank*RAM_BANK_SIZE + int(address-0xA000)) % len(b.ram)
+}
+
+func (b *mbcBase) ReadRom(address uint16) uint8 {
+	if address < 0x4000 {
+		return b.rom[b.romOffset(b.romBank0)+int(address)]
+	}
+	return b.rom[b.romOffset(b.romBankN)+int(address-0x4000)]
+}
+
+func (b *mbcBase) ReadRam(address uint16) uint8 {
+	if !b.ramEnabled || len(b.ram) == 0 {
+		return 0xFF
+	}
+	return b.ram[b.ramOffset(address)]
+}
+
+func (b *mbcBase) WriteRam(address uint16, value uint8) {
+	if !b.ramEnabled || len(b.ram) == 0 {
+		return
+	}
+	b.ram[b.ramOffset(address)] = value
+}
+
+func (b *mbcBase) RomBank0() int {
+	return b.romBank0 % b.romBanks()
+}
+
+func (b *mbcBase) RomBankN() int {
+	return b.romBankN % b.romBanks()
+}
+
+func (b *mbcBase) GetBank0() []uint8 {
+	offset := b.romOffset(b.romBank0)
+	return b.rom[offset : offset+ROM_BANK_SIZE]
+}
+
+func (b *mbcBase) GetBankN() []uint8 {
+	offset := b.romOffset(b.romBankN)
+	return b.rom[offset : offset+ROM_BANK_SIZE]
+}
+
+func (b *mbcBase) GetRamBank() []uint8 {
+	if len(b.ram) == 0 {
+		return nil
+	}
+	offset := b.ramOffset(0xA000)
+	return b.ram[offset:min(offset+RAM_BANK_SIZE, len(b.ram))]
+}
+
+func (b *mbcBase) GetRam() []uint8 {
+	return b.ram
+}
+
+func (b *mbcBase) SaveData(now time.Time) []uint8 {
+	return slices.Clone(b.ram)
+}
+
+// SaveState tells whether the .sav file is out of date: it changes whenever the save data does,
+// except for the time that passes on the clock of the cartridge. That is caught up with on
+// load anyway, so only the RAM and the times the game set the clock count.
+func SaveState(cart BatteryMbc) []uint8 {
+	if c, ok := cart.(clockSaveMbc); ok {
+		return c.saveState()
+	}
+	return cart.SaveData(time.Time{})
+}
+
+// ram followed by the number of times the game set the clock
+func clockSaveState(ram []uint8, clockWrites uint64) []uint8 {
+	return binary.LittleEndian.AppendUint64(slices.Clone(ram), clockWrites)
+}
+
+// Saves of a different size are loaded as far as they fit
+func (b *mbcBase) LoadSaveData(data []uint8) {
+	copy(b.ram, data)
+}
+
+// Cartridges without a bank controller: 32 KiB of ROM and optionally up to 8 KiB of RAM
+type NoMbc struct {
+	mbcBase
+}
+
+func NewNoMbc(rom *Rom) *NoMbc {
+	m := &NoMbc{mbcBase: newMbcBase(rom)}
+	m.ramEnabled = true
+	return m
+}
+
+func (m *NoMbc) WriteRom(address uint16, value uint8) {
+	// ROM is not writable and there are no registers to write to
+}
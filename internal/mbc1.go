@@ -0,0 +1,82 @@
+package internal
+
+import "bytes"
+
+// https://gbdev.io/pandocs/MBC1.html
+type Mbc1 struct {
+	mbcBase
+
+	bank1 uint8 // 0x2000-0x3FFF: lower 5 bits of the ROM bank
+	bank2 uint8 // 0x4000-0x5FFF: RAM bank or upper 2 bits of the ROM bank
+	mode  uint8 // 0x6000-0x7FFF: banking mode select
+
+	// MBC1M multicarts wire bank2 to ROM address lines 18-19 instead of 19-20,
+	// so only 4 bits of bank1 are used
+	multicart bool
+}
+
+func NewMbc1(rom *Rom) *Mbc1 {
+	return newMbc1(rom, false)
+}
+
+func newMbc1(rom *Rom, multicart bool) *Mbc1 {
+	m := &Mbc1{mbcBase: newMbcBase(rom)}
+	m.bank1 = 1
+	m.multicart = multicart
+	m.updateBanks()
+	return m
+}
+
+// MBC1M carts are 1 MiB and contain one game per 256 KiB, each with its own header.
+// The only reliable way to tell them apart is to look for a second Nintendo logo at bank 0x10.
+func isMbc1Multicart(rom []uint8) bool {
+	const secondGame = 0x10 * ROM_BANK_SIZE
+	const logoSize = 0x30
+	if len(rom) != 0x100000 {
+		return false
+	}
+	logo := rom[HEADER_LOGO : HEADER_LOGO+logoSize]
+	return bytes.Equal(logo, rom[secondGame+HEADER_LOGO:secondGame+HEADER_LOGO+logoSize])
+}
+
+func (m *Mbc1) WriteRom(address uint16, value uint8) {
+	switch {
+	case address < 0x2000:
+		m.ramEnabled = value&0x0F == 0x0A
+
+	case address < 0x4000:
+		m.bank1 = value & 0x1F
+		// bank 0 can not be mapped to 0x4000-0x7FFF, the check only sees the 5 bit register
+		if m.bank1 == 0 {
+			m.bank1 = 1
+		}
+
+	case address < 0x6000:
+		m.bank2 = value & 0x03
+
+	case address < 0x8000:
+		m.mode = value & 0x01
+	}
+	m.updateBanks()
+}
+
+func (m *Mbc1) updateBanks() {
+	shift := 5
+	bank1 := m.bank1
+	if m.multicart {
+		shift = 4
+		bank1 &= 0x0F
+	}
+
+	m.romBankN = int(m.bank2)<<shift | int(bank1)
+
+	// In mode 1 bank2 also applies to 0x0000-0x3FFF and the RAM,
+	// which is how 1 MiB+ ROMs reach banks 0x20/0x40/0x60 and 32 KiB RAMs their upper banks.
+	if m.mode == 1 {
+		m.romBank0 = int(m.bank2) << shift
+		m.ramBank = int(m.bank2)
+	} else {
+		m.romBank0 = 0
+		m.ramBank = 0
+	}
+}
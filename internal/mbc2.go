@@ -0,0 +1,47 @@
+package internal
+
+const MBC2_RAM_SIZE = 512
+
+// https://gbdev.io/pandocs/MBC2.html
+type Mbc2 struct {
+	mbcBase
+}
+
+func NewMbc2(rom *Rom) *Mbc2 {
+	m := &Mbc2{mbcBase: newMbcBase(rom)}
+	// the 512 half-byte RAM is built into the MBC, the header always reports no RAM
+	m.ram = make([]uint8, MBC2_RAM_SIZE)
+	return m
+}
+
+func (m *Mbc2) WriteRom(address uint16, value uint8) {
+	if address >= 0x4000 {
+		return
+	}
+
+	// bit 8 of the address decides which register is written
+	if GetBit16(address, 8) {
+		m.romBankN = int(value & 0x0F)
+		if m.romBankN == 0 {
+			m.romBankN = 1
+		}
+	} else {
+		m.ramEnabled = value&0x0F == 0x0A
+	}
+}
+
+// Only the lower 9 address bits are decoded, so the RAM repeats over 0xA000-0xBFFF
+func (m *Mbc2) ReadRam(address uint16) uint8 {
+	if !m.ramEnabled {
+		return 0xFF
+	}
+	// only the lower nibble is stored, the upper one is left floating and reads as 1s
+	return m.ram[(address-0xA000)&0x1FF] | 0xF0
+}
+
+func (m *Mbc2) WriteRam(address uint16, value uint8) {
+	if !m.ramEnabled {
+		return
+	}
+	m.ram[(address-0xA000)&0x1FF] = value & 0x0F
+}
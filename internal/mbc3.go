@@ -0,0 +1,110 @@
+package internal
+
+import "time"
+
+// https://gbdev.io/pandocs/MBC3.html
+type Mbc3 struct {
+	mbcBase
+
+	// 0x4000-0x5FFF: 0x00-0x03 selects a RAM bank, 0x08-0x0C an RTC register
+	ramBankOrRtc uint8
+
+	rtc         *Rtc   // nil for cartridges without a timer
+	clockWrites uint64 // RTC register writes by the game, see SaveState
+}
+
+func NewMbc3(rom *Rom) *Mbc3 {
+	m := &Mbc3{mbcBase: newMbcBase(rom)}
+	if rom.HasRtc() {
+		m.rtc = NewRtc()
+	}
+	return m
+}
+
+func (m *Mbc3) GetRtc() *Rtc {
+	return m.rtc
+}
+
+func (m *Mbc3) Tick(mCycles uint64) {
+	if m.rtc != nil {
+		m.rtc.Tick(mCycles)
+	}
+}
+
+func (m *Mbc3) rtcSelected() bool {
+	return m.ramBankOrRtc >= RTC_S && m.ramBankOrRtc <= RTC_DH
+}
+
+func (m *Mbc3) WriteRom(address uint16, value uint8) {
+	switch {
+	case address < 0x2000:
+		// enables both RAM and the RTC registers
+		m.ramEnabled = value&0x0F == 0x0A
+
+	case address < 0x4000:
+		m.romBankN = int(value & 0x7F)
+		if m.romBankN == 0 {
+			m.romBankN = 1
+		}
+
+	case address < 0x6000:
+		m.ramBankOrRtc = value
+		if value <= 0x03 {
+			m.ramBank = int(value)
+		}
+
+	case address < 0x8000:
+		if m.rtc != nil {
+			m.rtc.WriteLatch(value)
+		}
+	}
+}
+
+func (m *Mbc3) ReadRam(address uint16) uint8 {
+	if m.rtcSelected() {
+		if !m.ramEnabled || m.rtc == nil {
+			return 0xFF
+		}
+		return m.rtc.Read(m.ramBankOrRtc)
+	}
+	if m.ramBankOrRtc > 0x03 {
+		return 0xFF
+	}
+	return m.mbcBase.ReadRam(address)
+}
+
+func (m *Mbc3) WriteRam(address uint16, value uint8) {
+	if m.rtcSelected() {
+		if m.ramEnabled && m.rtc != nil {
+			m.rtc.Write(m.ramBankOrRtc, value)
+			m.clockWrites++
+		}
+		return
+	}
+	if m.ramBankOrRtc > 0x03 {
+		return
+	}
+	m.mbcBase.WriteRam(address, value)
+}
+
+// Carts with a clock store it behind the RAM in the .sav file
+func (m *Mbc3) SaveData(now time.Time) []uint8 {
+	data := m.mbcBase.SaveData(now)
+	if m.rtc != nil {
+		data = append(data, m.rtc.MarshalFooter(now)...)
+	}
+	return data
+}
+
+func (m *Mbc3) saveState() []uint8 {
+	return clockSaveState(m.ram, m.clockWrites)
+}
+
+func (m *Mbc3) LoadSaveData(data []uint8) {
+	m.mbcBase.LoadSaveData(data)
+	if m.rtc == nil || len(data) <= len(m.ram) {
+		return
+	}
+	// saves without or with a broken footer just keep the current time
+	_ = m.rtc.UnmarshalFooter(data[len(m.ram):], time.Now())
+}
@@ -0,0 +1,58 @@
+package internal
+
+// https://gbdev.io/pandocs/MBC5.html
+type Mbc5 struct {
+	mbcBase
+
+	// On rumble cartridges bit 3 of the RAM bank register drives the motor
+	// instead of selecting a bank
+	hasRumble bool
+	rumbleOn  bool
+	onRumble  func(on bool)
+}
+
+func NewMbc5(rom *Rom) *Mbc5 {
+	m := &Mbc5{mbcBase: newMbcBase(rom)}
+	m.hasRumble = rom.Header.CartridgeType.HasRumble()
+	return m
+}
+
+func (m *Mbc5) SetRumbleCallback(callback func(on bool)) {
+	m.onRumble = callback
+}
+
+func (m *Mbc5) IsRumbling() bool {
+	return m.rumbleOn
+}
+
+func (m *Mbc5) WriteRom(address uint16, value uint8) {
+	switch {
+	case address < 0x2000:
+		m.ramEnabled = value&0x0F == 0x0A
+
+	case address < 0x3000:
+		// lower 8 bits of the 9 bit ROM bank, unlike MBC1 bank 0 can be mapped here
+		m.romBankN = m.romBankN&0x100 | int(value)
+
+	case address < 0x4000:
+		m.romBankN = m.romBankN&0xFF | int(value&0x01)<<8
+
+	case address < 0x6000:
+		if m.hasRumble {
+			m.ramBank = int(value & 0x07)
+			m.setRumble(GetBit(value, 3))
+		} else {
+			m.ramBank = int(value & 0x0F)
+		}
+	}
+}
+
+func (m *Mbc5) setRumble(on bool) {
+	if on == m.rumbleOn {
+		return
+	}
+	m.rumbleOn = on
+	if m.onRumble != nil {
+		m.onRumble(on)
+	}
+}
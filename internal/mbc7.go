@@ -0,0 +1,121 @@
+package internal
+
+const (
+	MBC7_EEPROM_SIZE = 2 * EEPROM_WORDS
+
+	// Accelerometer reading when the Game Boy lies flat, each axis changes by about 0x70 per g
+	MBC7_ACCEL_CENTER = 0x81D0
+	MBC7_ACCEL_PER_G  = 0x70
+)
+
+// Cartridges with a tilt sensor
+type TiltMbc interface {
+	// x and y are in g, positive x tilts to the right and positive y towards the player
+	SetTilt(x, y float64)
+}
+
+// https://gbdev.io/pandocs/MBC7.html
+type Mbc7 struct {
+	mbcBase
+
+	// 0x0000-0x1FFF has to be 0x0A and 0x4000-0x5FFF 0x40 to access the registers
+	ramEnabled2 bool
+
+	tiltX, tiltY float64
+
+	// accelerometer values as read by the game, only updated on a latch
+	accelX, accelY uint16
+	accelErased    bool
+
+	eeprom *Eeprom
+}
+
+func NewMbc7(rom *Rom) *Mbc7 {
+	m := &Mbc7{mbcBase: newMbcBase(rom)}
+	// the cartridge RAM is the EEPROM, the header reports none
+	m.ram = make([]uint8, MBC7_EEPROM_SIZE)
+	for i := range m.ram {
+		m.ram[i] = 0xFF
+	}
+	m.eeprom = NewEeprom(m.ram)
+	m.accelX, m.accelY = 0x8000, 0x8000
+	return m
+}
+
+func (m *Mbc7) SetTilt(x, y float64) {
+	m.tiltX, m.tiltY = x, y
+}
+
+func (m *Mbc7) GetEeprom() *Eeprom {
+	return m.eeprom
+}
+
+func (m *Mbc7) WriteRom(address uint16, value uint8) {
+	switch {
+	case address < 0x2000:
+		m.ramEnabled = value == 0x0A
+		if !m.ramEnabled {
+			m.ramEnabled2 = false
+		}
+
+	case address < 0x4000:
+		m.romBankN = int(value & 0x7F)
+
+	case address < 0x6000:
+		m.ramEnabled2 = m.ramEnabled && value == 0x40
+	}
+}
+
+func (m *Mbc7) registersEnabled(address uint16) bool {
+	// only 0xA000-0xAFFF holds registers
+	return m.ramEnabled && m.ramEnabled2 && address < 0xB000
+}
+
+// the register is selected by bits 4-7 of the address
+func (m *Mbc7) ReadRam(address uint16) uint8 {
+	if !m.registersEnabled(address) {
+		return 0xFF
+	}
+	switch address >> 4 & 0x0F {
+	case 0x2:
+		return uint8(m.accelX)
+	case 0x3:
+		return uint8(m.accelX >> 8)
+	case 0x4:
+		return uint8(m.accelY)
+	case 0x5:
+		return uint8(m.accelY >> 8)
+	case 0x6:
+		return 0x00
+	case 0x8:
+		return m.eeprom.Pins()
+	}
+	return 0xFF
+}
+
+func (m *Mbc7) WriteRam(address uint16, value uint8) {
+	if !m.registersEnabled(address) {
+		return
+	}
+	switch address >> 4 & 0x0F {
+	case 0x0:
+		if value == 0x55 {
+			m.accelX, m.accelY = 0x8000, 0x8000
+			m.accelErased = true
+		}
+	case 0x1:
+		// only latches once after an erase
+		if value == 0xAA && m.accelErased {
+			m.accelX = accelValue(-m.tiltX)
+			m.accelY = accelValue(m.tiltY)
+			m.accelErased = false
+		}
+	case 0x8:
+		m.eeprom.SetPins(value)
+	}
+}
+
+func accelValue(g float64) uint16 {
+	g = max(-4, min(4, g))
+	return uint16(MBC7_ACCEL_CENTER + int(g*MBC7_ACCEL_PER_G))
+}
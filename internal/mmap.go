@@ -1,62 +1,83 @@
 package internal
 
 type Mmap struct {
-	bank0 [0x4000]uint8 // 16 KiB ROM bank 00
-	bankN [0x4000]uint8 // 16 KiB ROM Bank 01–NN
+	Cart Mbc // ROM banks 00-NN and External RAM, nil until a rom is loaded
 
-	vram   [0x2000]uint8 // 8 KiB Video RAM (VRAM)
-	extram [0x2000]uint8 // 8 KiB External RAM
+	vram [0x2000]uint8 // 8 KiB Video RAM (VRAM)
 
 	wram1 [0x1000]uint8 // 4 KiB Work RAM (WRAM)
 	wram2 [0x1000]uint8 // 4 KiB Work RAM (WRAM)
 
 	//Echoram [0x1e00]uint8 // Echo Ram (mirror of C000–DDFF)
 
-	Oam  [0xa0]uint8 //Object attribute memory (OAM)
-	nu   [0x60]uint8 //not usable
-	Io   Ioregs      // I/O Reg
-	Hram [0x7f]uint8 //high ram
-	Ie   uint8       //interrupt enable reg
+	Oam    [0xa0]uint8 //Object attribute memory (OAM)
+	nu     [0x60]uint8 //not usable
+	Io     Ioregs      // I/O Reg
+	Joypad Joypad
+	Hram   [0x7f]uint8 //high ram
+	Ie     uint8       //interrupt enable reg
 
 	Ppu *Ppu
-}
-
-func (m *Mmap) SetValueForRom(address uint16, value uint8) {
 
-	switch {
-	case address < 0x4000:
-		m.bank0[address] = value
+	BootRom        []uint8 // nil if the game is started directly
+	bootRomEnabled bool
+}
 
-	case address < 0x8000:
-		m.bankN[address-0x4000] = value
+func (m *Mmap) readCartRom(address uint16) uint8 {
+	if m.bootRomMapped(address) {
+		return m.BootRom[address]
 	}
+	if m.Cart == nil {
+		return 0xFF
+	}
+	if cart, ok := m.Cart.(bootMbc); ok && m.bootRomEnabled {
+		return cart.readRomDuringBoot(address)
+	}
+	return m.Cart.ReadRom(address)
 }
 
-func (m *Mmap) SetValue(address uint16, value uint8) {
+func (m *Mmap) readCartRam(address uint16) uint8 {
+	if m.Cart == nil {
+		return 0xFF
+	}
+	return m.Cart.ReadRam(address)
+}
 
-	//TODO: only in mbc0 afaik, so change when mbc implemented
-	if address < 0x8000 {
-		return // ROM is not writable
+// TickCart advances cartridge hardware that has its own clock
+func (m *Mmap) TickCart(mCycles uint64) {
+	if cart, ok := m.Cart.(ClockedMbc); ok {
+		cart.Tick(mCycles)
 	}
+}
 
-	// OAM DMA transfer
-	// Source:      $XX00-$XX9F   ;XX = $00 to $DF
-	// Destination: $FE00-$FE9F
+func NewMmap() *Mmap {
+	m := &Mmap{}
+	m.Io.OnWrite(0x46, m.startDma)
+	m.Io.OnWrite(BOOT_ROM_DISABLE_ADDR-0xFF00, m.disableBootRom)
+	m.Joypad.connectIo(&m.Io)
+	return m
+}
 
-	if address == 0xFF46 {
-		sourceStartAddr := uint16(value) << 8
-		for i := range 0x9f {
-			cpy, _ := m.ReadByteAt(sourceStartAddr + uint16(i))
-			m.SetValue(0xFE00+uint16(i), cpy)
-		}
+// OAM DMA transfer
+// Source:      $XX00-$XX9F   ;XX = $00 to $DF
+// Destination: $FE00-$FE9F
+func (m *Mmap) startDma(value uint8) {
+	m.Io.Store(0x46, value)
+	sourceStartAddr := uint16(value) << 8
+	for i := range uint16(len(m.Oam)) {
+		cpy, _ := m.ReadByteAtForced(sourceStartAddr + i)
+		m.Oam[i] = cpy // the DMA does not care about the PPU mode
 	}
+}
 
-	switch {
-	case address < 0x4000:
-		m.bank0[address] = value
+func (m *Mmap) SetValue(address uint16, value uint8) {
 
+	switch {
 	case address < 0x8000:
-		m.bankN[address-0x4000] = value
+		// writes to ROM go to the registers of the bank controller
+		if m.Cart != nil {
+			m.Cart.WriteRom(address, value)
+		}
 
 	case address < 0xA000:
 		if GetBit(m.Io.GetLCDC(), 7) && m.Ppu.CurrentMode == MODE_3 {
@@ -65,7 +86,9 @@ func (m *Mmap) SetValue(address uint16, value uint8) {
 		m.vram[address-0x8000] = value
 
 	case address < 0xC000:
-		m.extram[address-0xA000] = value
+		if m.Cart != nil {
+			m.Cart.WriteRam(address, value)
+		}
 
 	case address < 0xD000:
 		m.wram1[address-0xC000] = value
@@ -93,10 +116,10 @@ func (m *Mmap) SetValue(address uint16, value uint8) {
 		}
 
 	case address < 0xFF00:
-		m.nu[address-0xFEA0] = value
+		// prohibited area, writes are ignored
 
 	case address < 0xFF80:
-		m.Io.Regs[address-0xFF00] = value
+		m.Io.SetAtAdress(address-0xFF00, value)
 
 	case address < 0xFFFF:
 		m.Hram[address-0xFF80] = value
@@ -110,14 +133,9 @@ func (m *Mmap) SetValue(address uint16, value uint8) {
 func (m *Mmap) Read16At(address uint16) (data uint16, numReadBytes uint16) {
 
 	switch {
-	case address < 0x4000-1:
-		a1 := uint16(m.bank0[address])
-		a2 := uint16(m.bank0[address+1])
-		return uint16(a1 | a2<<8), 2
-
 	case address < 0x8000-1:
-		a1 := uint16(m.bankN[address-0x4000])
-		a2 := uint16(m.bankN[address-0x4000+1])
+		a1 := uint16(m.readCartRom(address))
+		a2 := uint16(m.readCartRom(address + 1))
 		return uint16(a1 | a2<<8), 2
 
 	case address < 0xA000-1:
@@ -129,8 +147,8 @@ func (m *Mmap) Read16At(address uint16) (data uint16, numReadBytes uint16) {
 		return uint16(a1 | a2<<8), 2
 
 	case address < 0xC000-1:
-		a1 := uint16(m.extram[address-0xa000])
-		a2 := uint16(m.extram[address-0xa000+1])
+		a1 := uint16(m.readCartRam(address))
+		a2 := uint16(m.readCartRam(address + 1))
 		return uint16(a1 | a2<<8), 2
 
 	case address < 0xD000-1:
@@ -171,11 +189,13 @@ func (m *Mmap) Read16At(address uint16) (data uint16, numReadBytes uint16) {
 		return 0xFF, 1
 
 	case address < 0xFF00-1:
-		return 0, 1
+		a1 := uint16(m.readProhibited(address))
+		a2 := uint16(m.readProhibited(address + 1))
+		return uint16(a1 | a2<<8), 2
 
 	case address < 0xFF80-1:
-		a1 := uint16(m.Io.Regs[address-0xFF00])
-		a2 := uint16(m.Io.Regs[address-0xFF00+1])
+		a1 := uint16(m.Io.GetAtAddress(address - 0xFF00))
+		a2 := uint16(m.Io.GetAtAddress(address - 0xFF00 + 1))
 		return uint16(a1 | a2<<8), 2
 
 	case address < 0xFFFE-1:
@@ -190,17 +210,14 @@ func (m *Mmap) Read16At(address uint16) (data uint16, numReadBytes uint16) {
 func (m *Mmap) ReadByteAtForced(address uint16) (val uint8, bytesRead uint16) {
 
 	switch {
-	case address < 0x4000:
-		return m.bank0[address], 1
-
 	case address < 0x8000:
-		return m.bankN[address-0x4000], 1
+		return m.readCartRom(address), 1
 
 	case address < 0xA000:
 		return m.vram[address-0x8000], 1
 
 	case address < 0xC000:
-		return m.extram[address-0xA000], 1
+		return m.readCartRam(address), 1
 
 	case address < 0xD000:
 		return m.wram1[address-0xC000], 1
@@ -222,11 +239,10 @@ func (m *Mmap) ReadByteAtForced(address uint16) (val uint8, bytesRead uint16) {
 		return m.Oam[address-0xFE00], 1
 
 	case address < 0xFF00:
-		return 0, 1
-		// return m.nu[address-0xFEA0], 1
+		return m.readProhibited(address), 1
 
 	case address < 0xFF80:
-		return m.Io.Regs[address-0xFF00], 1
+		return m.Io.GetAtAddress(address - 0xFF00), 1
 
 	case address < 0xFFFF:
 		return m.Hram[address-0xFF80], 1
@@ -240,11 +256,8 @@ func (m *Mmap) ReadByteAtForced(address uint16) (val uint8, bytesRead uint16) {
 func (m *Mmap) ReadByteAt(address uint16) (val uint8, bytesRead uint16) {
 
 	switch {
-	case address < 0x4000:
-		return m.bank0[address], 1
-
 	case address < 0x8000:
-		return m.bankN[address-0x4000], 1
+		return m.readCartRom(address), 1
 
 	case address < 0xA000:
 		if GetBit(m.Io.GetLCDC(), 7) && m.Ppu.CurrentMode == MODE_3 {
@@ -253,7 +266,7 @@ func (m *Mmap) ReadByteAt(address uint16) (val uint8, bytesRead uint16) {
 		return m.vram[address-0x8000], 1
 
 	case address < 0xC000:
-		return m.extram[address-0xA000], 1
+		return m.readCartRam(address), 1
 
 	case address < 0xD000:
 		return m.wram1[address-0xC000], 1
@@ -280,11 +293,10 @@ func (m *Mmap) ReadByteAt(address uint16) (val uint8, bytesRead uint16) {
 		return 0xFF, 1
 
 	case address < 0xFF00:
-		return 0, 1
-		// return m.nu[address-0xFEA0], 1
+		return m.readProhibited(address), 1
 
 	case address < 0xFF80:
-		return m.Io.Regs[address-0xFF00], 1
+		return m.Io.GetAtAddress(address - 0xFF00), 1
 
 	case address < 0xFFFF:
 		return m.Hram[address-0xFF80], 1
@@ -295,6 +307,24 @@ func (m *Mmap) ReadByteAt(address uint16) (val uint8, bytesRead uint16) {
 	return 0, 0
 }
 
+// readProhibited returns what the 0xFEA0-0xFEFF area reads as, which differs between models.
+// The DMG family returns 0 unless the PPU blocks OAM, the CGB (revision E) and AGB
+// repeat the upper nibble of the address.
+func (m *Mmap) readProhibited(address uint16) uint8 {
+	if m.Io.Model.IsCgb() {
+		nibble := uint8(address>>4) & 0x0F
+		return nibble<<4 | nibble
+	}
+	if m.oamBlocked() {
+		return 0xFF
+	}
+	return 0x00
+}
+
+func (m *Mmap) oamBlocked() bool {
+	return GetBit(m.Io.GetLCDC(), 7) && (m.Ppu.CurrentMode == MODE_2 || m.Ppu.CurrentMode == MODE_3)
+}
+
 func SetBit(ptr *uint8, bit uint8, cond bool) {
 	if cond {
 		*ptr |= (1 << bit)
@@ -326,13 +356,31 @@ func (m *Mmap) GetInterruptEnabledBit(bit InterruptFlags) bool {
 	}
 }
 
+// RomBank returns the cartridge bank mapped at address, 0 outside of the rom area and under the boot rom
+func (m *Mmap) RomBank(address uint16) int {
+	cart, ok := m.Cart.(BankedMbc)
+	if !ok || address >= 0x8000 || m.bootRomMapped(address) {
+		return 0
+	}
+	if address < 0x4000 {
+		return cart.RomBank0()
+	}
+	return cart.RomBankN()
+}
+
 // Getters for memory-mapped regions
 func (m *Mmap) GetBank0() []uint8 {
-	return m.bank0[:]
+	if m.Cart == nil {
+		return nil
+	}
+	return m.Cart.GetBank0()
 }
 
 func (m *Mmap) GetBank1() []uint8 {
-	return m.bankN[:]
+	if m.Cart == nil {
+		return nil
+	}
+	return m.Cart.GetBankN()
 }
 
 func (m *Mmap) GetVram() []uint8 {
@@ -340,7 +388,10 @@ func (m *Mmap) GetVram() []uint8 {
 }
 
 func (m *Mmap) GetExtram() []uint8 {
-	return m.extram[:]
+	if m.Cart == nil {
+		return nil
+	}
+	return m.Cart.GetRamBank()
 }
 
 func (m *Mmap) GetWram1() []uint8 {
@@ -0,0 +1,124 @@
+package internal
+
+// The MMM01 starts with the last 32 KiB of the rom mapped, which holds a menu with the MMM01 header.
+// The menu sets up the bank registers for the chosen game and locks them by setting the map bit,
+// from then on the cart behaves like an MBC1 limited to the area of that game.
+// https://gbdev.io/pandocs/MMM01.html
+type Mmm01 struct {
+	mbcBase
+
+	mapped bool // registers marked "before mapping" below can no longer be written
+
+	romBankLow  uint8 // 0x2000-0x3FFF bits 0-4
+	romBankMid  uint8 // 0x2000-0x3FFF bits 5-6, before mapping
+	romBankHigh uint8 // 0x4000-0x5FFF bits 4-5, before mapping
+	romBankMask uint8 // 0x6000-0x7FFF bits 2-5, before mapping: bits 1-4 of romBankLow that are fixed after mapping
+
+	ramBankLow  uint8 // 0x4000-0x5FFF bits 0-1
+	ramBankHigh uint8 // 0x4000-0x5FFF bits 2-3, before mapping
+	ramBankMask uint8 // 0x0000-0x1FFF bits 4-5, before mapping: bits of ramBankLow that are fixed after mapping
+
+	mode             uint8 // 0x6000-0x7FFF bit 0, like the MBC1 banking mode
+	modeWriteDisable bool  // 0x4000-0x5FFF bit 6, before mapping
+}
+
+// mmm01Header returns the data of the menu header at the start of the last 32 KiB if it declares an MMM01
+func mmm01Header(data []byte) []byte {
+	if len(data) < 4*ROM_BANK_SIZE || len(data)%(2*ROM_BANK_SIZE) != 0 {
+		return nil
+	}
+	menu := data[len(data)-2*ROM_BANK_SIZE:]
+	if CartridgeType(menu[HEADER_CARTRIDGE_TYPE]).Mapper() != MAPPER_MMM01 {
+		return nil
+	}
+	if computeHeaderChecksum(menu) != menu[HEADER_CHECKSUM] {
+		return nil
+	}
+	return menu
+}
+
+func NewMmm01(rom *Rom) *Mmm01 {
+	data := rom.GetData()
+
+	// some dumps put the menu first, the cart itself has it at the end
+	if mmm01Header(data) == nil && rom.Header.CartridgeType.Mapper() == MAPPER_MMM01 && len(data) > 2*ROM_BANK_SIZE {
+		data = append(append([]byte{}, data[2*ROM_BANK_SIZE:]...), data[:2*ROM_BANK_SIZE]...)
+	}
+
+	m := &Mmm01{mbcBase: newMbcBase(rom)}
+	m.rom = padRom(data)
+
+	// the RAM size is only correct in the menu header
+	if menu := mmm01Header(m.rom); menu != nil {
+		h := parseHeader(menu)
+		m.ram = make([]uint8, cartRamSize(&h))
+	}
+	m.updateBanks()
+	return m
+}
+
+func (m *Mmm01) WriteRom(address uint16, value uint8) {
+	switch {
+	case address < 0x2000:
+		m.ramEnabled = value&0x0F == 0x0A
+		if !m.mapped {
+			m.ramBankMask = value >> 4 & 0x03
+			m.mapped = GetBit(value, 6)
+		}
+
+	case address < 0x4000:
+		// bits covered by the mask keep the value they had when mapping
+		mask := m.romBankMask << 1
+		if !m.mapped {
+			mask = 0
+			m.romBankMid = value >> 5 & 0x03
+		}
+		m.romBankLow = m.romBankLow&mask | value&0x1F&^mask
+
+	case address < 0x6000:
+		mask := m.ramBankMask
+		if !m.mapped {
+			mask = 0
+			m.ramBankHigh = value >> 2 & 0x03
+			m.romBankHigh = value >> 4 & 0x03
+			m.modeWriteDisable = GetBit(value, 6)
+		}
+		m.ramBankLow = m.ramBankLow&mask | value&0x03&^mask
+
+	case address < 0x8000:
+		if !m.modeWriteDisable {
+			m.mode = value & 0x01
+		}
+		if !m.mapped {
+			m.romBankMask = value >> 2 & 0x0F
+		}
+	}
+	m.updateBanks()
+}
+
+func (m *Mmm01) updateBanks() {
+	if !m.mapped {
+		// all upper bank bits read as 1 until a game is mapped, which selects the menu
+		m.romBank0 = 0x1FE
+		m.romBankN = 0x1FF
+		m.ramBank = 0
+		return
+	}
+
+	outer := int(m.romBankHigh)<<7 | int(m.romBankMid)<<5
+	mask := m.romBankMask << 1
+
+	low := m.romBankLow
+	// like on the MBC1 bank 0 of the game can not be mapped to 0x4000-0x7FFF
+	if low&^mask == 0 {
+		low |= 0x01
+	}
+	m.romBankN = outer | int(low)
+	m.romBank0 = outer | int(m.romBankLow&mask)
+
+	ramLow := m.ramBankLow
+	if m.mode == 0 {
+		ramLow &= m.ramBankMask
+	}
+	m.ramBank = int(m.ramBankHigh)<<2 | int(ramLow)
+}
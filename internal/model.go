@@ -0,0 +1,220 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+)
+
+type Model uint8
+
+const (
+	MODEL_AUTO Model = iota // CGB for color games, DMG for everything else
+	MODEL_DMG0              // early japanese DMG with a different boot rom
+	MODEL_DMG
+	MODEL_MGB // Game Boy Pocket
+	MODEL_SGB
+	MODEL_SGB2
+	MODEL_CGB
+	MODEL_AGB // Game Boy Advance in Game Boy mode
+)
+
+var modelNames = map[Model]string{
+	MODEL_AUTO: "auto",
+	MODEL_DMG0: "dmg0",
+	MODEL_DMG:  "dmg",
+	MODEL_MGB:  "mgb",
+	MODEL_SGB:  "sgb",
+	MODEL_SGB2: "sgb2",
+	MODEL_CGB:  "cgb",
+	MODEL_AGB:  "agb",
+}
+
+var ErrUnknownModel = errors.New("unknown model")
+
+func (m Model) String() string {
+	if name, ok := modelNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("Model(%d)", uint8(m))
+}
+
+func ParseModel(name string) (Model, error) {
+	for m, n := range modelNames {
+		if strings.EqualFold(name, n) {
+			return m, nil
+		}
+	}
+	return MODEL_AUTO, fmt.Errorf("%w %q", ErrUnknownModel, name)
+}
+
+// Resolve picks the model to run a game on if m is MODEL_AUTO
+func (m Model) Resolve(h *Header) Model {
+	if m != MODEL_AUTO {
+		return m
+	}
+	if h != nil && h.IsCgb() {
+		return MODEL_CGB
+	}
+	return MODEL_DMG
+}
+
+func (m Model) IsCgb() bool {
+	return m == MODEL_CGB || m == MODEL_AGB
+}
+
+func (m Model) IsSgb() bool {
+	return m == MODEL_SGB || m == MODEL_SGB2
+}
+
+// State the boot rom of a model leaves behind when it jumps to 0x100
+// https://gbdev.io/pandocs/Power_Up_Sequence.html#cpu-registers
+type PowerOnState struct {
+	A, F, B, C, D, E, H, L uint8
+	SP, PC                 uint16
+
+	Io map[uint16]uint8
+
+	// Internal 16 bit divider in T-cycles, DIV is its upper byte
+	DivCounter uint16
+
+	// The boot roms hand over during vblank on line 153, where LY reads 0 after the first M-cycle.
+	// The DMG0 boot rom is done before that, its STAT still shows LY != LYC. Pandocs has no exact
+	// dot, these are the first ones that agree with STAT.
+	PpuLine uint8
+	PpuMode PpuMode
+	PpuDot  uint64
+}
+
+// Hardware registers after the DMG boot rom, other models only change a few of them
+// https://gbdev.io/pandocs/Power_Up_Sequence.html#hardware-registers
+var dmgPowerOnIo = map[uint16]uint8{
+	0xFF00: 0xCF, // P1
+	0xFF01: 0x00, // SB
+	0xFF02: 0x7E, // SC
+	0xFF05: 0x00, // TIMA
+	0xFF06: 0x00, // TMA
+	0xFF07: 0xF8, // TAC
+	0xFF0F: 0xE1, // IF
+	0xFF10: 0x80, // NR10
+	0xFF11: 0xBF, // NR11
+	0xFF12: 0xF3, // NR12
+	0xFF13: 0xFF, // NR13
+	0xFF14: 0xBF, // NR14
+	0xFF16: 0x3F, // NR21
+	0xFF17: 0x00, // NR22
+	0xFF18: 0xFF, // NR23
+	0xFF19: 0xBF, // NR24
+	0xFF1A: 0x7F, // NR30
+	0xFF1B: 0xFF, // NR31
+	0xFF1C: 0x9F, // NR32
+	0xFF1D: 0xFF, // NR33
+	0xFF1E: 0xBF, // NR34
+	0xFF20: 0xFF, // NR41
+	0xFF21: 0x00, // NR42
+	0xFF22: 0x00, // NR43
+	0xFF23: 0xBF, // NR44
+	0xFF24: 0x77, // NR50
+	0xFF25: 0xF3, // NR51
+	0xFF26: 0xF1, // NR52
+	0xFF40: 0x91, // LCDC
+	0xFF41: 0x85, // STAT
+	0xFF42: 0x00, // SCY
+	0xFF43: 0x00, // SCX
+	0xFF44: 0x00, // LY
+	0xFF45: 0x00, // LYC
+	0xFF46: 0xFF, // DMA
+	0xFF47: 0xFC, // BGP
+	0xFF48: 0xFF, // OBP0
+	0xFF49: 0xFF, // OBP1
+	0xFF4A: 0x00, // WY
+	0xFF4B: 0x00, // WX
+	0xFFFF: 0x00, // IE
+}
+
+var cgbPowerOnIo = map[uint16]uint8{
+	0xFF02: 0x7F, // SC
+	0xFF46: 0x00, // DMA
+	0xFF4D: 0x7E, // KEY1
+	0xFF4F: 0xFE, // VBK
+	0xFF51: 0xFF, // HDMA1
+	0xFF52: 0xFF, // HDMA2
+	0xFF53: 0xFF, // HDMA3
+	0xFF54: 0xFF, // HDMA4
+	0xFF55: 0xFF, // HDMA5
+	0xFF56: 0x3E, // RP
+	0xFF70: 0xF8, // SVBK
+}
+
+func withIo(base map[uint16]uint8, changes map[uint16]uint8) map[uint16]uint8 {
+	io := maps.Clone(base)
+	maps.Copy(io, changes)
+	return io
+}
+
+// PowerOnState returns the state after the boot rom for the cartridge with header h (which may be nil).
+// The DMG and MGB boot roms leave the half carry and carry flags set unless the header checksum is 0,
+// the CGB boot rom runs color games in CGB mode and everything else in DMG compatibility mode.
+//
+// Pandocs lists DIV and the PPU position as unknown for SGB and CGB: the SGB boot rom takes
+// longer the more header data it sends to the SNES, and the CGB boot rom depends on the
+// palette lookup for the title. The values below are those of a typical boot.
+func (m Model) PowerOnState(h *Header) PowerOnState {
+	m = m.Resolve(h)
+
+	var checksumFlags uint8
+	if h != nil && h.HeaderChecksum != 0 {
+		checksumFlags = 0x30
+	}
+	cgbMode := h != nil && h.IsCgb()
+
+	s := PowerOnState{
+		SP: 0xFFFE, PC: 0x0100,
+		Io:      dmgPowerOnIo,
+		PpuLine: 153,
+		PpuMode: MODE_1,
+		PpuDot:  4,
+	}
+
+	switch m {
+	case MODEL_DMG0:
+		s.A, s.F, s.B, s.C, s.D, s.E, s.H, s.L = 0x01, checksumFlags, 0xFF, 0x13, 0x00, 0xC1, 0x84, 0x03
+		s.Io = withIo(dmgPowerOnIo, map[uint16]uint8{0xFF41: 0x81})
+		s.DivCounter = 0x1830
+		s.PpuDot = 0
+
+	case MODEL_DMG:
+		s.A, s.F, s.B, s.C, s.D, s.E, s.H, s.L = 0x01, 0x80|checksumFlags, 0x00, 0x13, 0x00, 0xD8, 0x01, 0x4D
+		s.DivCounter = 0xABCC
+
+	case MODEL_MGB:
+		s.A, s.F, s.B, s.C, s.D, s.E, s.H, s.L = 0xFF, 0x80|checksumFlags, 0x00, 0x13, 0x00, 0xD8, 0x01, 0x4D
+		s.DivCounter = 0xABCC
+
+	case MODEL_SGB, MODEL_SGB2:
+		s.A, s.F, s.B, s.C, s.D, s.E, s.H, s.L = 0x01, 0x00, 0x00, 0x14, 0x00, 0x00, 0xC0, 0x60
+		if m == MODEL_SGB2 {
+			s.A = 0xFF
+		}
+		s.Io = withIo(dmgPowerOnIo, map[uint16]uint8{0xFF26: 0xF0})
+		s.DivCounter = 0xD85C
+
+	case MODEL_CGB, MODEL_AGB:
+		// in DMG mode BC and HL depend on whether the title has a built-in palette, these are the values without one
+		if cgbMode {
+			s.A, s.F, s.B, s.C, s.D, s.E, s.H, s.L = 0x11, 0x80, 0x00, 0x00, 0xFF, 0x56, 0x00, 0x0D
+			s.Io = withIo(dmgPowerOnIo, cgbPowerOnIo)
+		} else {
+			s.A, s.F, s.B, s.C, s.D, s.E, s.H, s.L = 0x11, 0x80, 0x00, 0x00, 0x00, 0x08, 0x00, 0x7C
+			s.Io = withIo(dmgPowerOnIo, withIo(cgbPowerOnIo, map[uint16]uint8{0xFF4D: 0xFF}))
+		}
+		// the AGB boot rom ends with an extra INC B, which also clears the zero flag
+		if m == MODEL_AGB {
+			s.B++
+			s.F = 0x00
+		}
+		s.DivCounter = 0x267C
+	}
+	return s
+}
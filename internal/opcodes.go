@@ -0,0 +1,414 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind of immediate that follows the opcode
+type Operand uint8
+
+const (
+	OPERAND_NONE Operand = iota
+	OPERAND_N8           // unsigned byte
+	OPERAND_N16          // little endian word
+	OPERAND_A8           // low byte of an address in 0xFF00-0xFFFF
+	OPERAND_A16          // address
+	OPERAND_E8           // signed byte added to SP
+	OPERAND_REL8         // signed jump distance from the next instruction
+)
+
+// Everything about an instruction that does not depend on the cpu state.
+// The cpu and the disassembler (package disasm) both work from OPCODES and CB_OPCODES.
+// https://gbdev.io/gb-opcodes/optables/
+type Opcode struct {
+	// RGBDS syntax, the immediate shows up as n8, n16, a8, a16 or e8
+	Mnemonic string
+	Operand  Operand
+	Length   uint8 // in bytes, including the opcode (and the 0xCB prefix)
+
+	// M-cycles, Cycles is the not taken case for conditional jumps, calls and returns
+	Cycles       uint8
+	BranchCycles uint8 // 0 if the instruction does not branch conditionally
+
+	// Effect on Z, N, H and C: the flag name if it depends on the result,
+	// 0 or 1 if it is always set to that and - if it is kept
+	Flags string
+
+	Illegal bool // locks up the cpu
+
+	exec func(cpu *Cpu)
+}
+
+var OPCODES = [256]Opcode{
+	0x00: {Mnemonic: "NOP", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x01: {Mnemonic: "LD BC, n16", Operand: OPERAND_N16, Length: 3, Cycles: 3, BranchCycles: 0, Flags: "----"},
+	0x02: {Mnemonic: "LD [BC], A", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x03: {Mnemonic: "INC BC", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x04: {Mnemonic: "INC B", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z0H-"},
+	0x05: {Mnemonic: "DEC B", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1H-"},
+	0x06: {Mnemonic: "LD B, n8", Operand: OPERAND_N8, Length: 2, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x07: {Mnemonic: "RLCA", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "000C"},
+	0x08: {Mnemonic: "LD [a16], SP", Operand: OPERAND_A16, Length: 3, Cycles: 5, BranchCycles: 0, Flags: "----"},
+	0x09: {Mnemonic: "ADD HL, BC", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "-0HC"},
+	0x0A: {Mnemonic: "LD A, [BC]", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x0B: {Mnemonic: "DEC BC", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x0C: {Mnemonic: "INC C", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z0H-"},
+	0x0D: {Mnemonic: "DEC C", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1H-"},
+	0x0E: {Mnemonic: "LD C, n8", Operand: OPERAND_N8, Length: 2, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x0F: {Mnemonic: "RRCA", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "000C"},
+	0x10: {Mnemonic: "STOP", Operand: OPERAND_NONE, Length: 2, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x11: {Mnemonic: "LD DE, n16", Operand: OPERAND_N16, Length: 3, Cycles: 3, BranchCycles: 0, Flags: "----"},
+	0x12: {Mnemonic: "LD [DE], A", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x13: {Mnemonic: "INC DE", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x14: {Mnemonic: "INC D", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z0H-"},
+	0x15: {Mnemonic: "DEC D", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1H-"},
+	0x16: {Mnemonic: "LD D, n8", Operand: OPERAND_N8, Length: 2, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x17: {Mnemonic: "RLA", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "000C"},
+	0x18: {Mnemonic: "JR e8", Operand: OPERAND_REL8, Length: 2, Cycles: 3, BranchCycles: 0, Flags: "----"},
+	0x19: {Mnemonic: "ADD HL, DE", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "-0HC"},
+	0x1A: {Mnemonic: "LD A, [DE]", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x1B: {Mnemonic: "DEC DE", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x1C: {Mnemonic: "INC E", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z0H-"},
+	0x1D: {Mnemonic: "DEC E", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1H-"},
+	0x1E: {Mnemonic: "LD E, n8", Operand: OPERAND_N8, Length: 2, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x1F: {Mnemonic: "RRA", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "000C"},
+	0x20: {Mnemonic: "JR NZ, e8", Operand: OPERAND_REL8, Length: 2, Cycles: 2, BranchCycles: 3, Flags: "----"},
+	0x21: {Mnemonic: "LD HL, n16", Operand: OPERAND_N16, Length: 3, Cycles: 3, BranchCycles: 0, Flags: "----"},
+	0x22: {Mnemonic: "LD [HL+], A", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x23: {Mnemonic: "INC HL", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x24: {Mnemonic: "INC H", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z0H-"},
+	0x25: {Mnemonic: "DEC H", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1H-"},
+	0x26: {Mnemonic: "LD H, n8", Operand: OPERAND_N8, Length: 2, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x27: {Mnemonic: "DAA", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z-0C"},
+	0x28: {Mnemonic: "JR Z, e8", Operand: OPERAND_REL8, Length: 2, Cycles: 2, BranchCycles: 3, Flags: "----"},
+	0x29: {Mnemonic: "ADD HL, HL", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "-0HC"},
+	0x2A: {Mnemonic: "LD A, [HL+]", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x2B: {Mnemonic: "DEC HL", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x2C: {Mnemonic: "INC L", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z0H-"},
+	0x2D: {Mnemonic: "DEC L", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1H-"},
+	0x2E: {Mnemonic: "LD L, n8", Operand: OPERAND_N8, Length: 2, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x2F: {Mnemonic: "CPL", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "-11-"},
+	0x30: {Mnemonic: "JR NC, e8", Operand: OPERAND_REL8, Length: 2, Cycles: 2, BranchCycles: 3, Flags: "----"},
+	0x31: {Mnemonic: "LD SP, n16", Operand: OPERAND_N16, Length: 3, Cycles: 3, BranchCycles: 0, Flags: "----"},
+	0x32: {Mnemonic: "LD [HL-], A", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x33: {Mnemonic: "INC SP", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x34: {Mnemonic: "INC [HL]", Operand: OPERAND_NONE, Length: 1, Cycles: 3, BranchCycles: 0, Flags: "Z0H-"},
+	0x35: {Mnemonic: "DEC [HL]", Operand: OPERAND_NONE, Length: 1, Cycles: 3, BranchCycles: 0, Flags: "Z1H-"},
+	0x36: {Mnemonic: "LD [HL], n8", Operand: OPERAND_N8, Length: 2, Cycles: 3, BranchCycles: 0, Flags: "----"},
+	0x37: {Mnemonic: "SCF", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "-001"},
+	0x38: {Mnemonic: "JR C, e8", Operand: OPERAND_REL8, Length: 2, Cycles: 2, BranchCycles: 3, Flags: "----"},
+	0x39: {Mnemonic: "ADD HL, SP", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "-0HC"},
+	0x3A: {Mnemonic: "LD A, [HL-]", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x3B: {Mnemonic: "DEC SP", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x3C: {Mnemonic: "INC A", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z0H-"},
+	0x3D: {Mnemonic: "DEC A", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1H-"},
+	0x3E: {Mnemonic: "LD A, n8", Operand: OPERAND_N8, Length: 2, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x3F: {Mnemonic: "CCF", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "-00C"},
+	0x40: {Mnemonic: "LD B, B", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x41: {Mnemonic: "LD B, C", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x42: {Mnemonic: "LD B, D", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x43: {Mnemonic: "LD B, E", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x44: {Mnemonic: "LD B, H", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x45: {Mnemonic: "LD B, L", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x46: {Mnemonic: "LD B, [HL]", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x47: {Mnemonic: "LD B, A", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x48: {Mnemonic: "LD C, B", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x49: {Mnemonic: "LD C, C", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x4A: {Mnemonic: "LD C, D", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x4B: {Mnemonic: "LD C, E", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x4C: {Mnemonic: "LD C, H", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x4D: {Mnemonic: "LD C, L", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x4E: {Mnemonic: "LD C, [HL]", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x4F: {Mnemonic: "LD C, A", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x50: {Mnemonic: "LD D, B", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x51: {Mnemonic: "LD D, C", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x52: {Mnemonic: "LD D, D", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x53: {Mnemonic: "LD D, E", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x54: {Mnemonic: "LD D, H", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x55: {Mnemonic: "LD D, L", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x56: {Mnemonic: "LD D, [HL]", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x57: {Mnemonic: "LD D, A", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x58: {Mnemonic: "LD E, B", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x59: {Mnemonic: "LD E, C", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x5A: {Mnemonic: "LD E, D", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x5B: {Mnemonic: "LD E, E", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x5C: {Mnemonic: "LD E, H", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x5D: {Mnemonic: "LD E, L", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x5E: {Mnemonic: "LD E, [HL]", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x5F: {Mnemonic: "LD E, A", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x60: {Mnemonic: "LD H, B", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x61: {Mnemonic: "LD H, C", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x62: {Mnemonic: "LD H, D", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x63: {Mnemonic: "LD H, E", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x64: {Mnemonic: "LD H, H", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x65: {Mnemonic: "LD H, L", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x66: {Mnemonic: "LD H, [HL]", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x67: {Mnemonic: "LD H, A", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x68: {Mnemonic: "LD L, B", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x69: {Mnemonic: "LD L, C", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x6A: {Mnemonic: "LD L, D", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x6B: {Mnemonic: "LD L, E", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x6C: {Mnemonic: "LD L, H", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x6D: {Mnemonic: "LD L, L", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x6E: {Mnemonic: "LD L, [HL]", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x6F: {Mnemonic: "LD L, A", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x70: {Mnemonic: "LD [HL], B", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x71: {Mnemonic: "LD [HL], C", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x72: {Mnemonic: "LD [HL], D", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x73: {Mnemonic: "LD [HL], E", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x74: {Mnemonic: "LD [HL], H", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x75: {Mnemonic: "LD [HL], L", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x76: {Mnemonic: "HALT", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x77: {Mnemonic: "LD [HL], A", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x78: {Mnemonic: "LD A, B", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x79: {Mnemonic: "LD A, C", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x7A: {Mnemonic: "LD A, D", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x7B: {Mnemonic: "LD A, E", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x7C: {Mnemonic: "LD A, H", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x7D: {Mnemonic: "LD A, L", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x7E: {Mnemonic: "LD A, [HL]", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0x7F: {Mnemonic: "LD A, A", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0x80: {Mnemonic: "ADD A, B", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z0HC"},
+	0x81: {Mnemonic: "ADD A, C", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z0HC"},
+	0x82: {Mnemonic: "ADD A, D", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z0HC"},
+	0x83: {Mnemonic: "ADD A, E", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z0HC"},
+	0x84: {Mnemonic: "ADD A, H", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z0HC"},
+	0x85: {Mnemonic: "ADD A, L", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z0HC"},
+	0x86: {Mnemonic: "ADD A, [HL]", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "Z0HC"},
+	0x87: {Mnemonic: "ADD A, A", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z0HC"},
+	0x88: {Mnemonic: "ADC A, B", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z0HC"},
+	0x89: {Mnemonic: "ADC A, C", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z0HC"},
+	0x8A: {Mnemonic: "ADC A, D", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z0HC"},
+	0x8B: {Mnemonic: "ADC A, E", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z0HC"},
+	0x8C: {Mnemonic: "ADC A, H", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z0HC"},
+	0x8D: {Mnemonic: "ADC A, L", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z0HC"},
+	0x8E: {Mnemonic: "ADC A, [HL]", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "Z0HC"},
+	0x8F: {Mnemonic: "ADC A, A", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z0HC"},
+	0x90: {Mnemonic: "SUB A, B", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1HC"},
+	0x91: {Mnemonic: "SUB A, C", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1HC"},
+	0x92: {Mnemonic: "SUB A, D", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1HC"},
+	0x93: {Mnemonic: "SUB A, E", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1HC"},
+	0x94: {Mnemonic: "SUB A, H", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1HC"},
+	0x95: {Mnemonic: "SUB A, L", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1HC"},
+	0x96: {Mnemonic: "SUB A, [HL]", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "Z1HC"},
+	0x97: {Mnemonic: "SUB A, A", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1HC"},
+	0x98: {Mnemonic: "SBC A, B", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1HC"},
+	0x99: {Mnemonic: "SBC A, C", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1HC"},
+	0x9A: {Mnemonic: "SBC A, D", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1HC"},
+	0x9B: {Mnemonic: "SBC A, E", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1HC"},
+	0x9C: {Mnemonic: "SBC A, H", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1HC"},
+	0x9D: {Mnemonic: "SBC A, L", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1HC"},
+	0x9E: {Mnemonic: "SBC A, [HL]", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "Z1HC"},
+	0x9F: {Mnemonic: "SBC A, A", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1HC"},
+	0xA0: {Mnemonic: "AND A, B", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z010"},
+	0xA1: {Mnemonic: "AND A, C", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z010"},
+	0xA2: {Mnemonic: "AND A, D", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z010"},
+	0xA3: {Mnemonic: "AND A, E", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z010"},
+	0xA4: {Mnemonic: "AND A, H", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z010"},
+	0xA5: {Mnemonic: "AND A, L", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z010"},
+	0xA6: {Mnemonic: "AND A, [HL]", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "Z010"},
+	0xA7: {Mnemonic: "AND A, A", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z010"},
+	0xA8: {Mnemonic: "XOR A, B", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z000"},
+	0xA9: {Mnemonic: "XOR A, C", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z000"},
+	0xAA: {Mnemonic: "XOR A, D", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z000"},
+	0xAB: {Mnemonic: "XOR A, E", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z000"},
+	0xAC: {Mnemonic: "XOR A, H", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z000"},
+	0xAD: {Mnemonic: "XOR A, L", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z000"},
+	0xAE: {Mnemonic: "XOR A, [HL]", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "Z000"},
+	0xAF: {Mnemonic: "XOR A, A", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z000"},
+	0xB0: {Mnemonic: "OR A, B", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z000"},
+	0xB1: {Mnemonic: "OR A, C", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z000"},
+	0xB2: {Mnemonic: "OR A, D", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z000"},
+	0xB3: {Mnemonic: "OR A, E", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z000"},
+	0xB4: {Mnemonic: "OR A, H", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z000"},
+	0xB5: {Mnemonic: "OR A, L", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z000"},
+	0xB6: {Mnemonic: "OR A, [HL]", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "Z000"},
+	0xB7: {Mnemonic: "OR A, A", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z000"},
+	0xB8: {Mnemonic: "CP A, B", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1HC"},
+	0xB9: {Mnemonic: "CP A, C", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1HC"},
+	0xBA: {Mnemonic: "CP A, D", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1HC"},
+	0xBB: {Mnemonic: "CP A, E", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1HC"},
+	0xBC: {Mnemonic: "CP A, H", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1HC"},
+	0xBD: {Mnemonic: "CP A, L", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1HC"},
+	0xBE: {Mnemonic: "CP A, [HL]", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "Z1HC"},
+	0xBF: {Mnemonic: "CP A, A", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "Z1HC"},
+	0xC0: {Mnemonic: "RET NZ", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 5, Flags: "----"},
+	0xC1: {Mnemonic: "POP BC", Operand: OPERAND_NONE, Length: 1, Cycles: 3, BranchCycles: 0, Flags: "----"},
+	0xC2: {Mnemonic: "JP NZ, a16", Operand: OPERAND_A16, Length: 3, Cycles: 3, BranchCycles: 4, Flags: "----"},
+	0xC3: {Mnemonic: "JP a16", Operand: OPERAND_A16, Length: 3, Cycles: 4, BranchCycles: 0, Flags: "----"},
+	0xC4: {Mnemonic: "CALL NZ, a16", Operand: OPERAND_A16, Length: 3, Cycles: 3, BranchCycles: 6, Flags: "----"},
+	0xC5: {Mnemonic: "PUSH BC", Operand: OPERAND_NONE, Length: 1, Cycles: 4, BranchCycles: 0, Flags: "----"},
+	0xC6: {Mnemonic: "ADD A, n8", Operand: OPERAND_N8, Length: 2, Cycles: 2, BranchCycles: 0, Flags: "Z0HC"},
+	0xC7: {Mnemonic: "RST $00", Operand: OPERAND_NONE, Length: 1, Cycles: 4, BranchCycles: 0, Flags: "----"},
+	0xC8: {Mnemonic: "RET Z", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 5, Flags: "----"},
+	0xC9: {Mnemonic: "RET", Operand: OPERAND_NONE, Length: 1, Cycles: 4, BranchCycles: 0, Flags: "----"},
+	0xCA: {Mnemonic: "JP Z, a16", Operand: OPERAND_A16, Length: 3, Cycles: 3, BranchCycles: 4, Flags: "----"},
+	0xCB: {Mnemonic: "PREFIX", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0xCC: {Mnemonic: "CALL Z, a16", Operand: OPERAND_A16, Length: 3, Cycles: 3, BranchCycles: 6, Flags: "----"},
+	0xCD: {Mnemonic: "CALL a16", Operand: OPERAND_A16, Length: 3, Cycles: 6, BranchCycles: 0, Flags: "----"},
+	0xCE: {Mnemonic: "ADC A, n8", Operand: OPERAND_N8, Length: 2, Cycles: 2, BranchCycles: 0, Flags: "Z0HC"},
+	0xCF: {Mnemonic: "RST $08", Operand: OPERAND_NONE, Length: 1, Cycles: 4, BranchCycles: 0, Flags: "----"},
+	0xD0: {Mnemonic: "RET NC", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 5, Flags: "----"},
+	0xD1: {Mnemonic: "POP DE", Operand: OPERAND_NONE, Length: 1, Cycles: 3, BranchCycles: 0, Flags: "----"},
+	0xD2: {Mnemonic: "JP NC, a16", Operand: OPERAND_A16, Length: 3, Cycles: 3, BranchCycles: 4, Flags: "----"},
+	0xD3: {Illegal: true, Length: 1},
+	0xD4: {Mnemonic: "CALL NC, a16", Operand: OPERAND_A16, Length: 3, Cycles: 3, BranchCycles: 6, Flags: "----"},
+	0xD5: {Mnemonic: "PUSH DE", Operand: OPERAND_NONE, Length: 1, Cycles: 4, BranchCycles: 0, Flags: "----"},
+	0xD6: {Mnemonic: "SUB A, n8", Operand: OPERAND_N8, Length: 2, Cycles: 2, BranchCycles: 0, Flags: "Z1HC"},
+	0xD7: {Mnemonic: "RST $10", Operand: OPERAND_NONE, Length: 1, Cycles: 4, BranchCycles: 0, Flags: "----"},
+	0xD8: {Mnemonic: "RET C", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 5, Flags: "----"},
+	0xD9: {Mnemonic: "RETI", Operand: OPERAND_NONE, Length: 1, Cycles: 4, BranchCycles: 0, Flags: "----"},
+	0xDA: {Mnemonic: "JP C, a16", Operand: OPERAND_A16, Length: 3, Cycles: 3, BranchCycles: 4, Flags: "----"},
+	0xDB: {Illegal: true, Length: 1},
+	0xDC: {Mnemonic: "CALL C, a16", Operand: OPERAND_A16, Length: 3, Cycles: 3, BranchCycles: 6, Flags: "----"},
+	0xDD: {Illegal: true, Length: 1},
+	0xDE: {Mnemonic: "SBC A, n8", Operand: OPERAND_N8, Length: 2, Cycles: 2, BranchCycles: 0, Flags: "Z1HC"},
+	0xDF: {Mnemonic: "RST $18", Operand: OPERAND_NONE, Length: 1, Cycles: 4, BranchCycles: 0, Flags: "----"},
+	0xE0: {Mnemonic: "LDH [a8], A", Operand: OPERAND_A8, Length: 2, Cycles: 3, BranchCycles: 0, Flags: "----"},
+	0xE1: {Mnemonic: "POP HL", Operand: OPERAND_NONE, Length: 1, Cycles: 3, BranchCycles: 0, Flags: "----"},
+	0xE2: {Mnemonic: "LDH [C], A", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0xE3: {Illegal: true, Length: 1},
+	0xE4: {Illegal: true, Length: 1},
+	0xE5: {Mnemonic: "PUSH HL", Operand: OPERAND_NONE, Length: 1, Cycles: 4, BranchCycles: 0, Flags: "----"},
+	0xE6: {Mnemonic: "AND A, n8", Operand: OPERAND_N8, Length: 2, Cycles: 2, BranchCycles: 0, Flags: "Z010"},
+	0xE7: {Mnemonic: "RST $20", Operand: OPERAND_NONE, Length: 1, Cycles: 4, BranchCycles: 0, Flags: "----"},
+	0xE8: {Mnemonic: "ADD SP, e8", Operand: OPERAND_E8, Length: 2, Cycles: 4, BranchCycles: 0, Flags: "00HC"},
+	0xE9: {Mnemonic: "JP HL", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0xEA: {Mnemonic: "LD [a16], A", Operand: OPERAND_A16, Length: 3, Cycles: 4, BranchCycles: 0, Flags: "----"},
+	0xEB: {Illegal: true, Length: 1},
+	0xEC: {Illegal: true, Length: 1},
+	0xED: {Illegal: true, Length: 1},
+	0xEE: {Mnemonic: "XOR A, n8", Operand: OPERAND_N8, Length: 2, Cycles: 2, BranchCycles: 0, Flags: "Z000"},
+	0xEF: {Mnemonic: "RST $28", Operand: OPERAND_NONE, Length: 1, Cycles: 4, BranchCycles: 0, Flags: "----"},
+	0xF0: {Mnemonic: "LDH A, [a8]", Operand: OPERAND_A8, Length: 2, Cycles: 3, BranchCycles: 0, Flags: "----"},
+	0xF1: {Mnemonic: "POP AF", Operand: OPERAND_NONE, Length: 1, Cycles: 3, BranchCycles: 0, Flags: "ZNHC"},
+	0xF2: {Mnemonic: "LDH A, [C]", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0xF3: {Mnemonic: "DI", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0xF4: {Illegal: true, Length: 1},
+	0xF5: {Mnemonic: "PUSH AF", Operand: OPERAND_NONE, Length: 1, Cycles: 4, BranchCycles: 0, Flags: "----"},
+	0xF6: {Mnemonic: "OR A, n8", Operand: OPERAND_N8, Length: 2, Cycles: 2, BranchCycles: 0, Flags: "Z000"},
+	0xF7: {Mnemonic: "RST $30", Operand: OPERAND_NONE, Length: 1, Cycles: 4, BranchCycles: 0, Flags: "----"},
+	0xF8: {Mnemonic: "LD HL, SP+e8", Operand: OPERAND_E8, Length: 2, Cycles: 3, BranchCycles: 0, Flags: "00HC"},
+	0xF9: {Mnemonic: "LD SP, HL", Operand: OPERAND_NONE, Length: 1, Cycles: 2, BranchCycles: 0, Flags: "----"},
+	0xFA: {Mnemonic: "LD A, [a16]", Operand: OPERAND_A16, Length: 3, Cycles: 4, BranchCycles: 0, Flags: "----"},
+	0xFB: {Mnemonic: "EI", Operand: OPERAND_NONE, Length: 1, Cycles: 1, BranchCycles: 0, Flags: "----"},
+	0xFC: {Illegal: true, Length: 1},
+	0xFD: {Illegal: true, Length: 1},
+	0xFE: {Mnemonic: "CP A, n8", Operand: OPERAND_N8, Length: 2, Cycles: 2, BranchCycles: 0, Flags: "Z1HC"},
+	0xFF: {Mnemonic: "RST $38", Operand: OPERAND_NONE, Length: 1, Cycles: 4, BranchCycles: 0, Flags: "----"},
+}
+
+var CB_OPCODES [256]Opcode
+
+var cbRotateMnemonics = [8]string{"RLC", "RRC", "RL", "RR", "SLA", "SRA", "SWAP", "SRL"}
+
+func init() {
+	for i := range OPCODES {
+		if OPCODES[i].Illegal {
+			OPCODES[i].exec = (*Cpu).lock
+		} else {
+			OPCODES[i].exec = baseExec[i]
+		}
+	}
+
+	// the cb page is regular: bits 0-2 pick the register, bits 3-5 the operation or bit
+	for i := range CB_OPCODES {
+		code := uint8(i)
+		reg := Reg8(code & 0x07)
+		bit := (code >> 3) & 0x07
+		regName := reg8Name[reg]
+
+		op := Opcode{Length: 2, Cycles: 2, Flags: "----"}
+		if reg == REG_MEM_HL {
+			op.Cycles = 4
+		}
+		switch code >> 6 {
+		case 0:
+			op.Mnemonic = fmt.Sprintf("%s %s", cbRotateMnemonics[bit], regName)
+			op.Flags = "Z00C"
+			if bit == 6 {
+				op.Flags = "Z000"
+			}
+		case 1:
+			op.Mnemonic = fmt.Sprintf("BIT %d, %s", bit, regName)
+			op.Flags = "Z01-"
+			if reg == REG_MEM_HL {
+				op.Cycles = 3
+			}
+		case 2:
+			op.Mnemonic = fmt.Sprintf("RES %d, %s", bit, regName)
+		case 3:
+			op.Mnemonic = fmt.Sprintf("SET %d, %s", bit, regName)
+		}
+		op.exec = cbExec(code)
+		CB_OPCODES[i] = op
+	}
+}
+
+// Decode looks up the instruction at address, read has to return the byte at an address
+func Decode(read func(address uint16) uint8, address uint16) *Opcode {
+	code := read(address)
+	if code == 0xCB {
+		return &CB_OPCODES[read(address+1)]
+	}
+	return &OPCODES[code]
+}
+
+// Immediate returns the operand that follows the opcode, 0 for instructions without one
+func (o *Opcode) Immediate(read func(address uint16) uint8, address uint16) uint16 {
+	switch o.Operand {
+	case OPERAND_N16, OPERAND_A16:
+		return uint16(read(address+1)) | uint16(read(address+2))<<8
+	case OPERAND_NONE:
+		return 0
+	}
+	return uint16(read(address + 1))
+}
+
+var operandPlaceholders = map[Operand]string{
+	OPERAND_N8:   "n8",
+	OPERAND_N16:  "n16",
+	OPERAND_A8:   "a8",
+	OPERAND_A16:  "a16",
+	OPERAND_E8:   "e8",
+	OPERAND_REL8: "e8",
+}
+
+// Format writes the instruction at address with its immediate filled in
+func (o *Opcode) Format(immediate uint16, address uint16) string {
+	if o.Illegal {
+		return "ILLEGAL"
+	}
+	switch o.Operand {
+	case OPERAND_N8:
+		return o.WithOperand(fmt.Sprintf("$%02X", immediate))
+	case OPERAND_N16, OPERAND_A16:
+		return o.WithOperand(fmt.Sprintf("$%04X", immediate))
+	case OPERAND_A8:
+		return o.WithOperand(fmt.Sprintf("$FF%02X", immediate))
+	case OPERAND_E8:
+		return o.WithOperand(fmt.Sprintf("%d", int8(immediate)))
+	case OPERAND_REL8:
+		return o.WithOperand(fmt.Sprintf("$%04X", o.Target(immediate, address)))
+	}
+	return o.Mnemonic
+}
+
+// WithOperand writes the instruction with operand in place of the immediate, like a label name
+func (o *Opcode) WithOperand(operand string) string {
+	placeholder := operandPlaceholders[o.Operand]
+	if placeholder == "" {
+		return o.Mnemonic
+	}
+	// SP+e8 with a negative offset
+	if strings.HasPrefix(operand, "-") && strings.Contains(o.Mnemonic, "+"+placeholder) {
+		placeholder = "+" + placeholder
+	}
+	return strings.Replace(o.Mnemonic, placeholder, operand, 1)
+}
+
+// Target returns the address a relative jump at address goes to
+func (o *Opcode) Target(immediate uint16, address uint16) uint16 {
+	return address + uint16(o.Length) + uint16(int8(immediate))
+}
@@ -0,0 +1,345 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var PATCH_EXTENSIONS = []string{".ips", ".ups", ".bps"}
+
+var (
+	ErrPatchUnknown   = errors.New("unknown patch format")
+	ErrPatchCorrupt   = errors.New("patch is corrupt")
+	ErrPatchCrc       = errors.New("patch checksum mismatch")
+	ErrPatchSourceCrc = errors.New("rom does not match the checksum expected by the patch")
+	ErrPatchTargetCrc = errors.New("patched rom does not match the checksum stored in the patch")
+)
+
+// FindPatch returns the .ips, .ups or .bps file next to the rom with the same base name, or "" if there is none.
+// Roms from archives are matched by their own name as well as by the archive name.
+func FindPatch(r *Rom) string {
+	if r.Path == "" {
+		return ""
+	}
+	dir := filepath.Dir(r.Path)
+	bases := []string{strings.TrimSuffix(r.Path, filepath.Ext(r.Path))}
+	if r.Entry != "" {
+		entry := filepath.Base(filepath.FromSlash(r.Entry))
+		bases = append(bases, filepath.Join(dir, strings.TrimSuffix(entry, filepath.Ext(entry))))
+	}
+	for _, base := range bases {
+		for _, ext := range PATCH_EXTENSIONS {
+			if info, err := os.Stat(base + ext); err == nil && !info.IsDir() {
+				return base + ext
+			}
+		}
+	}
+	return ""
+}
+
+// ApplyPatchFile patches the rom in memory, the rom file itself is left alone
+func (r *Rom) ApplyPatchFile(path string) error {
+	patch, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading patch %q: %w", path, err)
+	}
+	data, err := ApplyPatch(r.data, patch)
+	if err != nil {
+		return fmt.Errorf("applying patch %q: %w", path, err)
+	}
+	patched, err := NewRomFromBytes(data)
+	if err != nil {
+		return fmt.Errorf("applying patch %q: %w", path, err)
+	}
+	r.data = patched.data
+	r.Header = patched.Header
+	r.Patch = path
+	return nil
+}
+
+// ApplyPatch returns a patched copy of rom, the format is detected from the patch header
+func ApplyPatch(rom []byte, patch []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(patch, []byte("PATCH")):
+		return applyIps(rom, patch)
+	case bytes.HasPrefix(patch, []byte("UPS1")):
+		return applyUps(rom, patch)
+	case bytes.HasPrefix(patch, []byte("BPS1")):
+		return applyBps(rom, patch)
+	}
+	return nil, ErrPatchUnknown
+}
+
+// https://zerosoft.zophar.net/ips.php
+func applyIps(rom []byte, patch []byte) ([]byte, error) {
+	out := bytes.Clone(rom)
+	p := patch[5:]
+
+	for {
+		if len(p) < 3 {
+			return nil, ErrPatchCorrupt
+		}
+		if string(p[:3]) == "EOF" {
+			p = p[3:]
+			break
+		}
+		if len(p) < 5 {
+			return nil, ErrPatchCorrupt
+		}
+		offset := int(p[0])<<16 | int(p[1])<<8 | int(p[2])
+		size := int(p[3])<<8 | int(p[4])
+		p = p[5:]
+
+		var record []byte
+		if size == 0 {
+			// run length encoded record
+			if len(p) < 3 {
+				return nil, ErrPatchCorrupt
+			}
+			record = bytes.Repeat(p[2:3], int(p[0])<<8|int(p[1]))
+			p = p[3:]
+		} else {
+			if len(p) < size {
+				return nil, ErrPatchCorrupt
+			}
+			record = p[:size]
+			p = p[size:]
+		}
+
+		if end := offset + len(record); end > len(out) {
+			out = append(out, make([]byte, end-len(out))...)
+		}
+		copy(out[offset:], record)
+	}
+
+	// an optional size after EOF truncates the rom
+	if len(p) >= 3 {
+		size := int(p[0])<<16 | int(p[1])<<8 | int(p[2])
+		if size < len(out) {
+			out = out[:size]
+		}
+	}
+	return out, nil
+}
+
+// Variable length integers used by UPS and BPS
+func readPatchNumber(p []byte, pos *int) (int, error) {
+	value, shift := 0, 1
+	for {
+		if *pos >= len(p) || shift > 1<<49 {
+			return 0, ErrPatchCorrupt
+		}
+		x := p[*pos]
+		*pos++
+		value += int(x&0x7F) * shift
+		if x&0x80 != 0 {
+			return value, nil
+		}
+		shift <<= 7
+		value += shift
+	}
+}
+
+// UPS and BPS end with the CRC32 of the source, of the target and of the patch itself
+type patchFooter struct {
+	source, target, patch uint32
+}
+
+func readPatchFooter(patch []byte) (patchFooter, error) {
+	if len(patch) < 4+12 {
+		return patchFooter{}, ErrPatchCorrupt
+	}
+	f := patch[len(patch)-12:]
+	footer := patchFooter{
+		source: binary.LittleEndian.Uint32(f[0:]),
+		target: binary.LittleEndian.Uint32(f[4:]),
+		patch:  binary.LittleEndian.Uint32(f[8:]),
+	}
+	if crc32.ChecksumIEEE(patch[:len(patch)-4]) != footer.patch {
+		return patchFooter{}, ErrPatchCrc
+	}
+	return footer, nil
+}
+
+func (f patchFooter) checkSource(rom []byte) error {
+	if crc := crc32.ChecksumIEEE(rom); crc != f.source {
+		return fmt.Errorf("%w: got %08x, expected %08x", ErrPatchSourceCrc, crc, f.source)
+	}
+	return nil
+}
+
+func (f patchFooter) checkTarget(out []byte) error {
+	if crc := crc32.ChecksumIEEE(out); crc != f.target {
+		return fmt.Errorf("%w: got %08x, expected %08x", ErrPatchTargetCrc, crc, f.target)
+	}
+	return nil
+}
+
+// UPS hunks xor the rom with the patch, which is why they can also be applied in reverse.
+// http://individual.utoronto.ca/dmeunier/ups-spec.pdf
+func applyUps(rom []byte, patch []byte) ([]byte, error) {
+	footer, err := readPatchFooter(patch)
+	if err != nil {
+		return nil, err
+	}
+	if err := footer.checkSource(rom); err != nil {
+		return nil, err
+	}
+
+	pos := 4
+	sourceSize, err := readPatchNumber(patch, &pos)
+	if err != nil {
+		return nil, err
+	}
+	targetSize, err := readPatchNumber(patch, &pos)
+	if err != nil {
+		return nil, err
+	}
+	if sourceSize != len(rom) || targetSize > MAX_ROM_SIZE {
+		return nil, ErrPatchCorrupt
+	}
+
+	out := make([]byte, targetSize)
+	copy(out, rom)
+
+	end := len(patch) - 12
+	offset := 0
+	for pos < end {
+		skip, err := readPatchNumber(patch, &pos)
+		if err != nil {
+			return nil, err
+		}
+		offset += skip
+		for {
+			if pos >= end {
+				return nil, ErrPatchCorrupt
+			}
+			x := patch[pos]
+			pos++
+			// a hunk ends with a 0, which also skips a byte
+			if x == 0 {
+				offset++
+				break
+			}
+			if offset < len(out) {
+				out[offset] ^= x
+			}
+			offset++
+		}
+	}
+
+	if err := footer.checkTarget(out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BPS actions, the lower 2 bits of each action number
+const (
+	BPS_SOURCE_READ = 0
+	BPS_TARGET_READ = 1
+	BPS_SOURCE_COPY = 2
+	BPS_TARGET_COPY = 3
+)
+
+// https://github.com/blakesmith/rombp/blob/master/docs/bps_spec.md
+func applyBps(rom []byte, patch []byte) ([]byte, error) {
+	footer, err := readPatchFooter(patch)
+	if err != nil {
+		return nil, err
+	}
+	if err := footer.checkSource(rom); err != nil {
+		return nil, err
+	}
+
+	pos := 4
+	var sizes [3]int // source, target and metadata size
+	for i := range sizes {
+		if sizes[i], err = readPatchNumber(patch, &pos); err != nil {
+			return nil, err
+		}
+	}
+	if sizes[0] != len(rom) || sizes[1] > MAX_ROM_SIZE {
+		return nil, ErrPatchCorrupt
+	}
+	pos += sizes[2] // metadata is not used
+
+	out := make([]byte, sizes[1])
+	end := len(patch) - 12
+	outPos, sourceRel, targetRel := 0, 0, 0
+
+	// relative offsets store the sign in bit 0
+	relative := func(offset *int) error {
+		d, err := readPatchNumber(patch, &pos)
+		if err != nil {
+			return err
+		}
+		if d&1 != 0 {
+			*offset -= d >> 1
+		} else {
+			*offset += d >> 1
+		}
+		return nil
+	}
+
+	for pos < end {
+		action, err := readPatchNumber(patch, &pos)
+		if err != nil {
+			return nil, err
+		}
+		length := action>>2 + 1
+		if outPos+length > len(out) {
+			return nil, ErrPatchCorrupt
+		}
+
+		switch action & 3 {
+		case BPS_SOURCE_READ:
+			if outPos+length > len(rom) {
+				return nil, ErrPatchCorrupt
+			}
+			copy(out[outPos:], rom[outPos:outPos+length])
+
+		case BPS_TARGET_READ:
+			if pos+length > end {
+				return nil, ErrPatchCorrupt
+			}
+			copy(out[outPos:], patch[pos:pos+length])
+			pos += length
+
+		case BPS_SOURCE_COPY:
+			if err := relative(&sourceRel); err != nil {
+				return nil, err
+			}
+			if sourceRel < 0 || sourceRel+length > len(rom) {
+				return nil, ErrPatchCorrupt
+			}
+			copy(out[outPos:], rom[sourceRel:sourceRel+length])
+			sourceRel += length
+
+		case BPS_TARGET_COPY:
+			if err := relative(&targetRel); err != nil {
+				return nil, err
+			}
+			if targetRel < 0 || targetRel >= outPos {
+				return nil, ErrPatchCorrupt
+			}
+			// the ranges may overlap to repeat a pattern, so copy byte by byte
+			for i := range length {
+				out[outPos+i] = out[targetRel+i]
+			}
+			targetRel += length
+		}
+		outPos += length
+	}
+
+	if err := footer.checkTarget(out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
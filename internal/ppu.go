@@ -27,6 +27,7 @@ type Ppu struct {
 
 	CurrentMode PpuMode
 	CurrentDot  uint64
+	lastLine    bool // line 153, where LY already reads 0 after the first M-cycle
 
 	Cpu *Cpu
 
@@ -102,9 +103,37 @@ func (p *Ppu) Restart(screenMultiplier int) {
 	p.running = true
 	p.CurrentDot = 0
 	p.CurrentMode = MODE_2
+	p.lastLine = false
 
 }
 
+// SetPhase moves the PPU to a dot of line and sets LY to match, STAT is left to the caller
+func (p *Ppu) SetPhase(line uint8, mode PpuMode, dot uint64) {
+	p.CurrentMode = mode
+	p.CurrentDot = dot
+	p.lastLine = line == 153 && dot >= 4
+	if p.lastLine {
+		line = 0
+	}
+	p.Cpu.Memory.Io.SetLY(line)
+}
+
+// ConnectIo hands the registers the PPU owns over to it, call it after every cpu restart
+func (p *Ppu) ConnectIo() {
+	p.Cpu.Memory.Io.OnWrite(0x45, p.writeLYC)
+}
+
+// the comparison with LY happens all the time, not only when LY changes
+func (p *Ppu) writeLYC(value uint8) {
+	io := &p.Cpu.Memory.Io
+	wasEqual := io.GetSTATBit(STAT_LY_EQ_LYC)
+	io.SetLYC(value)
+	io.SetSTATBit(STAT_LY_EQ_LYC, io.GetLY() == value)
+	if !wasEqual && io.GetSTATBit(STAT_LY_EQ_LYC) && io.GetSTATBit(STAT_LYC_INT) {
+		io.SetInterruptFlagBit(LCD, true)
+	}
+}
+
 func (p *Ppu) Step(ranMCyclesThisStep uint64) {
 
 	mode3Duration := uint64(172) + uint64(p.Cpu.Memory.Io.GetSCX())%8 //+ Num Sprites*8
@@ -114,27 +143,35 @@ func (p *Ppu) Step(ranMCyclesThisStep uint64) {
 	ranDotsThisCPUStep := ranMCyclesThisStep * 4
 	for i := uint64(0); i < ranDotsThisCPUStep; i++ {
 		p.CurrentDot++
-		if p.CurrentDot < mode2Duration && p.CurrentMode != MODE_2 {
-			p.Cpu.Memory.Io.SetSTATBit(STAT_PPU_MODE_LSB, false)
-			p.Cpu.Memory.Io.SetSTATBit(STAT_PPU_MODE_MSB, true)
-			p.CurrentMode = MODE_2
-			if p.Cpu.Memory.Io.GetSTATBit(STAT_MODE_2_INT) {
-				p.Cpu.Memory.Io.SetInterruptFlagBit(LCD, true)
-			}
-		} else if p.CurrentDot >= mode2Duration && p.CurrentDot < mode2Duration+mode3Duration && p.CurrentMode != MODE_3 {
-			p.CurrentMode = MODE_3
-			p.Cpu.Memory.Io.SetSTATBit(STAT_PPU_MODE_LSB, true)
-			p.Cpu.Memory.Io.SetSTATBit(STAT_PPU_MODE_MSB, true)
-		} else if p.CurrentDot >= mode2Duration+mode3Duration && p.CurrentDot < mode2Duration+mode3Duration+mode0Duration && p.CurrentDot < 456 && p.CurrentMode != MODE_0 {
-
-			p.CurrentMode = MODE_0
-			p.Cpu.Memory.Io.SetSTATBit(STAT_PPU_MODE_LSB, false)
-			p.Cpu.Memory.Io.SetSTATBit(STAT_PPU_MODE_MSB, false)
-			if p.Cpu.Memory.Io.GetSTATBit(STAT_MODE_0_INT) {
-				p.Cpu.Memory.Io.SetInterruptFlagBit(LCD, true)
+		// modes 2, 3 and 0 only happen on the visible lines, vblank stays in mode 1
+		if p.Cpu.Memory.Io.GetLY() < 144 && !p.lastLine {
+			if p.CurrentDot < mode2Duration && p.CurrentMode != MODE_2 {
+				p.Cpu.Memory.Io.SetSTATBit(STAT_PPU_MODE_LSB, false)
+				p.Cpu.Memory.Io.SetSTATBit(STAT_PPU_MODE_MSB, true)
+				p.CurrentMode = MODE_2
+				if p.Cpu.Memory.Io.GetSTATBit(STAT_MODE_2_INT) {
+					p.Cpu.Memory.Io.SetInterruptFlagBit(LCD, true)
+				}
+			} else if p.CurrentDot >= mode2Duration && p.CurrentDot < mode2Duration+mode3Duration && p.CurrentMode != MODE_3 {
+				p.CurrentMode = MODE_3
+				p.Cpu.Memory.Io.SetSTATBit(STAT_PPU_MODE_LSB, true)
+				p.Cpu.Memory.Io.SetSTATBit(STAT_PPU_MODE_MSB, true)
+			} else if p.CurrentDot >= mode2Duration+mode3Duration && p.CurrentDot < mode2Duration+mode3Duration+mode0Duration && p.CurrentDot < 456 && p.CurrentMode != MODE_0 {
+
+				p.CurrentMode = MODE_0
+				p.Cpu.Memory.Io.SetSTATBit(STAT_PPU_MODE_LSB, false)
+				p.Cpu.Memory.Io.SetSTATBit(STAT_PPU_MODE_MSB, false)
+				if p.Cpu.Memory.Io.GetSTATBit(STAT_MODE_0_INT) {
+					p.Cpu.Memory.Io.SetInterruptFlagBit(LCD, true)
+				}
 			}
 		}
-		if p.CurrentDot >= 456 {
+		if p.CurrentDot >= 456 && p.lastLine {
+			// the next frame starts, LY has been 0 since the start of line 153.
+			// Mode 2 begins on the next dot like on every other line.
+			p.CurrentDot = 0
+			p.lastLine = false
+		} else if p.CurrentDot >= 456 {
 
 			p.CurrentDot = 0
 			p.Cpu.Memory.Io.SetLY(p.Cpu.Memory.Io.GetLY() + 1)
@@ -159,45 +196,48 @@ func (p *Ppu) Step(ranMCyclesThisStep uint64) {
 				}
 			}
 
-			if p.Cpu.Memory.Io.GetLY() > 153 {
+			p.compareLY()
 
-				p.Cpu.Memory.Io.SetLY(0)
-				p.Cpu.Memory.Io.SetSTATBit(STAT_PPU_MODE_LSB, false)
-				p.Cpu.Memory.Io.SetSTATBit(STAT_PPU_MODE_MSB, true)
-				p.CurrentMode = MODE_2
-			}
-
-			p.Cpu.Memory.Io.SetSTATBit(STAT_LY_EQ_LYC, p.Cpu.Memory.Io.GetLY() == p.Cpu.Memory.Io.GetLYC())
-			if p.Cpu.Memory.Io.GetSTATBit(STAT_LY_EQ_LYC) && p.Cpu.Memory.Io.GetSTATBit(STAT_LYC_INT) {
-				p.Cpu.Memory.Io.SetInterruptFlagBit(LCD, true)
-			}
+		}
 
+		// on line 153 LY goes back to 0 after the first M-cycle
+		if p.CurrentDot == 4 && p.Cpu.Memory.Io.GetLY() == 153 {
+			p.Cpu.Memory.Io.SetLY(0)
+			p.lastLine = true
+			p.compareLY()
 		}
 	}
 
 }
 
-var tileColor1 = color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
-var tileColor2 = color.RGBA{0xAA, 0xAA, 0xAA, 0xFF}
-var tileColor3 = color.RGBA{0x55, 0x55, 0x55, 0xFF}
-var tileColor4 = color.RGBA{0x00, 0x00, 0x00, 0xFF}
+func (p *Ppu) compareLY() {
+	p.Cpu.Memory.Io.SetSTATBit(STAT_LY_EQ_LYC, p.Cpu.Memory.Io.GetLY() == p.Cpu.Memory.Io.GetLYC())
+	if p.Cpu.Memory.Io.GetSTATBit(STAT_LY_EQ_LYC) && p.Cpu.Memory.Io.GetSTATBit(STAT_LYC_INT) {
+		p.Cpu.Memory.Io.SetInterruptFlagBit(LCD, true)
+	}
+}
+
+var DEFAULT_PALETTE = Palette{
+	{0xFF, 0xFF, 0xFF, 0xFF},
+	{0xAA, 0xAA, 0xAA, 0xFF},
+	{0x55, 0x55, 0x55, 0xFF},
+	{0x00, 0x00, 0x00, 0xFF},
+}
+
+var palette = DEFAULT_PALETTE
+
+// SetPalette changes the shades the screen is drawn with
+func SetPalette(p Palette) {
+	palette = p
+}
 
 // TODO: implement palette selection
 // TODO: when obj, some bits are transparent
 func getTileColor(bits int) color.RGBA {
-
-	switch bits {
-	case 0:
-		return tileColor1
-	case 1:
-		return tileColor2
-	case 2:
-		return tileColor3
-	case 3:
-		return tileColor4
-	default:
+	if bits < 0 || bits >= len(palette) {
 		return color.RGBA{255, 0, 0, 255}
 	}
+	return palette[bits]
 }
 
 func (p *Ppu) FillWindowMapData() {
@@ -593,6 +633,11 @@ func (p *Ppu) RenderBackgroundMapViewer() {
 var texData = []uint8{255, 255, 255, 255}
 
 func (p *Ppu) Render() {
+	if p.Cpu.Stop {
+		p.renderBlank()
+		return
+	}
+
 	clear(p.viewportBuf)
 	// TODO: with current rendering this shows white screen 99% of the time
 	// maybe we need to set ly = 0 when LCDC.7 is set to off? or this just doesnt work
@@ -741,6 +786,25 @@ func (p *Ppu) Render() {
 
 }
 
+// The LCD shows the lightest shade while the cpu is stopped
+func (p *Ppu) renderBlank() {
+	for i := range p.viewportBuf {
+		p.viewportBuf[i] = palette[0]
+	}
+	gl.BindTexture(gl.TEXTURE_2D, p.ViewPortTex)
+	gl.TexImage2D(
+		gl.TEXTURE_2D,
+		0,
+		gl.RGBA,
+		int32(GB_WINDOW_WIDTH),
+		int32(GB_WINDOW_HEIGHT),
+		0,
+		gl.RGBA,
+		gl.UNSIGNED_BYTE,
+		gl.Ptr(p.viewportBuf))
+	gl.DrawArrays(gl.TRIANGLES, 0, 6)
+}
+
 func extractRect(src []color.RGBA, x, y, w, h, stride int) []color.RGBA {
 	dest := make([]color.RGBA, w*h)
 	for cH := 0; cH < h; cH++ {
@@ -0,0 +1,214 @@
+package internal
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Per game fixes for dumps whose header lies or leaves something out.
+// The database ships in quirks.json and can be extended with LoadQuirks.
+// A rom with an entry is loaded even if its header checksum or size codes are broken.
+//
+// A file is a JSON list of entries, everything but the key is optional:
+//
+//	[{
+//	    "title": "GAME TITLE",
+//	    "global_checksum": "0x1234",
+//	    "mapper": "mbc1m",
+//	    "model": "sgb",
+//	    "battery": true,
+//	    "rtc": false,
+//	    "rom_size_code": "0x05",
+//	    "ram_size_code": "0x03",
+//	    "palette": ["#E0F8D0", "#88C070", "#346856", "#081820"],
+//	    "note": "why the entry exists"
+//	}]
+//
+// or a .toml file with the same keys in [[quirk]] tables, see parseQuirksToml.
+type Quirk struct {
+	Mapper      Mapper   // MAPPER_UNKNOWN keeps the detected one
+	Model       Model    // MODEL_AUTO keeps the model the user asked for
+	Battery     *bool    // nil keeps what the cartridge type says
+	Rtc         *bool    // nil keeps what the cartridge type says
+	RomSizeCode *uint8   // nil keeps the header byte at 0x148
+	RamSizeCode *uint8   // nil keeps the header byte at 0x149
+	Palette     *Palette // nil keeps DEFAULT_PALETTE
+}
+
+// Quirks are keyed by the header title and global checksum
+type QuirkKey struct {
+	Title          string
+	GlobalChecksum uint16
+}
+
+var QUIRKS = map[QuirkKey]Quirk{}
+
+var ErrQuirkEntry = errors.New("invalid quirk entry")
+
+//go:embed quirks.json
+var embeddedQuirks []byte
+
+func init() {
+	if err := addQuirks(embeddedQuirks); err != nil {
+		panic(fmt.Sprintf("embedded quirks.json: %v", err))
+	}
+}
+
+type quirkEntry struct {
+	Title          string   `json:"title"`
+	GlobalChecksum string   `json:"global_checksum"`
+	Mapper         string   `json:"mapper"`
+	Model          string   `json:"model"`
+	Battery        *bool    `json:"battery"`
+	Rtc            *bool    `json:"rtc"`
+	RomSizeCode    string   `json:"rom_size_code"`
+	RamSizeCode    string   `json:"ram_size_code"`
+	Palette        []string `json:"palette"`
+	Note           string   `json:"note"`
+}
+
+// LoadQuirks adds the entries of a JSON or, for .toml files, TOML file to QUIRKS.
+// They replace built-in entries with the same key.
+func LoadQuirks(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading quirks %q: %w", path, err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		var entries []quirkEntry
+		if entries, err = parseQuirksToml(data); err == nil {
+			err = addQuirkEntries(entries)
+		}
+	} else {
+		err = addQuirks(data)
+	}
+	if err != nil {
+		return fmt.Errorf("loading quirks %q: %w", path, err)
+	}
+	return nil
+}
+
+func addQuirks(data []byte) error {
+	var entries []quirkEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	return addQuirkEntries(entries)
+}
+
+func addQuirkEntries(entries []quirkEntry) error {
+	// nothing is added if any entry is broken
+	parsed := make(map[QuirkKey]Quirk, len(entries))
+	for i, e := range entries {
+		key, q, err := e.parse()
+		if err != nil {
+			return fmt.Errorf("entry %d (%q): %w", i, e.Title, err)
+		}
+		parsed[key] = q
+	}
+	for key, q := range parsed {
+		QUIRKS[key] = q
+	}
+	return nil
+}
+
+func (e *quirkEntry) parse() (QuirkKey, Quirk, error) {
+	var q Quirk
+	checksum, err := strconv.ParseUint(e.GlobalChecksum, 0, 16)
+	if err != nil {
+		return QuirkKey{}, q, fmt.Errorf("%w: global_checksum %q", ErrQuirkEntry, e.GlobalChecksum)
+	}
+	key := QuirkKey{e.Title, uint16(checksum)}
+
+	if e.Mapper != "" {
+		if q.Mapper, err = ParseMapper(e.Mapper); err != nil {
+			return key, q, err
+		}
+	}
+	if e.Model != "" {
+		if q.Model, err = ParseModel(e.Model); err != nil {
+			return key, q, err
+		}
+	}
+	q.Battery = e.Battery
+	q.Rtc = e.Rtc
+	if e.RomSizeCode != "" {
+		if q.RomSizeCode, err = parseSizeCode(e.RomSizeCode); err != nil {
+			return key, q, err
+		}
+		if _, ok := (&Header{RomSizeCode: *q.RomSizeCode}).RomSize(); !ok {
+			return key, q, fmt.Errorf("%w: 0x%02x", ErrRomSizeCode, *q.RomSizeCode)
+		}
+	}
+	if e.RamSizeCode != "" {
+		if q.RamSizeCode, err = parseSizeCode(e.RamSizeCode); err != nil {
+			return key, q, err
+		}
+		if _, ok := (&Header{RamSizeCode: *q.RamSizeCode}).RamSize(); !ok {
+			return key, q, fmt.Errorf("%w: 0x%02x", ErrRamSizeCode, *q.RamSizeCode)
+		}
+	}
+	if e.Palette != nil {
+		p, err := ParsePalette(e.Palette)
+		if err != nil {
+			return key, q, err
+		}
+		q.Palette = &p
+	}
+	return key, q, nil
+}
+
+func parseSizeCode(s string) (*uint8, error) {
+	code, err := strconv.ParseUint(s, 0, 8)
+	if err != nil {
+		return nil, fmt.Errorf("%w: size code %q", ErrQuirkEntry, s)
+	}
+	c := uint8(code)
+	return &c, nil
+}
+
+// Quirk returns the database entry of the rom, the zero Quirk if there is none
+func (r *Rom) Quirk() Quirk {
+	return QUIRKS[QuirkKey{r.Header.Title, r.Header.GlobalChecksum}]
+}
+
+func (r *Rom) HasBattery() bool {
+	if q := r.Quirk(); q.Battery != nil {
+		return *q.Battery
+	}
+	return r.Header.CartridgeType.HasBattery()
+}
+
+func (r *Rom) HasRtc() bool {
+	if q := r.Quirk(); q.Rtc != nil {
+		return *q.Rtc
+	}
+	return r.Header.CartridgeType.HasRtc()
+}
+
+// Four shades from lightest to darkest
+type Palette [4]color.RGBA
+
+var ErrPalette = errors.New("palette needs four #RRGGBB colors")
+
+func ParsePalette(colors []string) (Palette, error) {
+	var p Palette
+	if len(colors) != len(p) {
+		return p, fmt.Errorf("%w: got %d", ErrPalette, len(colors))
+	}
+	for i, c := range colors {
+		rgb, err := strconv.ParseUint(strings.TrimPrefix(c, "#"), 16, 32)
+		if err != nil || len(c) != 7 || c[0] != '#' {
+			return p, fmt.Errorf("%w: %q", ErrPalette, c)
+		}
+		p[i] = color.RGBA{uint8(rgb >> 16), uint8(rgb >> 8), uint8(rgb), 0xFF}
+	}
+	return p, nil
+}
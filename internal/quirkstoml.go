@@ -0,0 +1,194 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrQuirkToml = errors.New("invalid quirks toml")
+
+// an integer value, kept as written
+type tomlInt string
+
+// parseQuirksToml reads the TOML form of a quirks file, every entry is a [[quirk]] table:
+//
+//	[[quirk]]
+//	title = "GAME TITLE"
+//	global_checksum = 0x1234
+//	mapper = "mbc1m"
+//	battery = true
+//	palette = ["#E0F8D0", "#88C070", "#346856", "#081820"]
+//
+// Only what the entries need is understood: strings, booleans, integers (kept as their text,
+// they are parsed like the JSON strings) and single line arrays of strings.
+func parseQuirksToml(data []byte) ([]quirkEntry, error) {
+	var entries []quirkEntry
+	var seen map[string]bool
+	for i, line := range strings.Split(string(data), "\n") {
+		lineErr := func(format string, args ...any) error {
+			return fmt.Errorf("%w: line %d: %s", ErrQuirkToml, i+1, fmt.Sprintf(format, args...))
+		}
+
+		text := strings.TrimSpace(line)
+		if text == "" || text[0] == '#' {
+			continue
+		}
+		if text[0] == '[' && !strings.Contains(text, "=") {
+			if header, _, _ := strings.Cut(text, "#"); strings.TrimSpace(header) != "[[quirk]]" {
+				return nil, lineErr("only [[quirk]] tables are supported, got %s", text)
+			}
+			entries = append(entries, quirkEntry{})
+			seen = map[string]bool{}
+			continue
+		}
+
+		key, rest, ok := strings.Cut(text, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, lineErr("expected key = value")
+		}
+		if len(entries) == 0 {
+			return nil, lineErr("%s outside of a [[quirk]] table", key)
+		}
+		if seen[key] {
+			return nil, lineErr("%s is set twice", key)
+		}
+		seen[key] = true
+
+		value, rest, err := parseTomlValue(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, lineErr("%s: %v", key, err)
+		}
+		if rest = strings.TrimSpace(rest); rest != "" && rest[0] != '#' {
+			return nil, lineErr("unexpected %q after the value of %s", rest, key)
+		}
+		if err := entries[len(entries)-1].setToml(key, value); err != nil {
+			return nil, lineErr("%v", err)
+		}
+	}
+	return entries, nil
+}
+
+// parseTomlValue reads the value at the start of s and returns what follows it.
+// Strings come back as string, integers as their text in a tomlInt, arrays as []string.
+func parseTomlValue(s string) (any, string, error) {
+	switch {
+	case s == "":
+		return nil, "", errors.New("missing value")
+	case s[0] == '"' || s[0] == '\'':
+		return parseTomlString(s)
+	case s[0] == '[':
+		var values []string
+		s = strings.TrimSpace(s[1:])
+		for !strings.HasPrefix(s, "]") {
+			if s == "" {
+				return nil, "", errors.New("arrays have to end on the same line")
+			}
+			value, rest, err := parseTomlString(s)
+			if err != nil {
+				return nil, "", err
+			}
+			values = append(values, value)
+			s = strings.TrimSpace(rest)
+			if strings.HasPrefix(s, ",") {
+				s = strings.TrimSpace(s[1:])
+			} else if !strings.HasPrefix(s, "]") {
+				return nil, "", errors.New("expected , or ] in array")
+			}
+		}
+		return values, s[1:], nil
+	}
+
+	end := strings.IndexAny(s, " \t#")
+	if end < 0 {
+		end = len(s)
+	}
+	word, rest := s[:end], s[end:]
+	switch word {
+	case "true":
+		return true, rest, nil
+	case "false":
+		return false, rest, nil
+	}
+	if _, err := strconv.ParseInt(word, 0, 64); err != nil {
+		return nil, "", fmt.Errorf("unsupported value %q", word)
+	}
+	return tomlInt(strings.ReplaceAll(word, "_", "")), rest, nil
+}
+
+// Basic strings use the escapes of Go strings, literal strings in single quotes have none
+func parseTomlString(s string) (string, string, error) {
+	if s == "" || (s[0] != '"' && s[0] != '\'') {
+		return "", "", errors.New("expected a string")
+	}
+	if s[0] == '\'' {
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", "", errors.New("unterminated string")
+		}
+		return s[1 : end+1], s[end+2:], nil
+	}
+	for end := 1; end < len(s); end++ {
+		switch s[end] {
+		case '\\':
+			end++
+		case '"':
+			value, err := strconv.Unquote(s[:end+1])
+			return value, s[end+1:], err
+		}
+	}
+	return "", "", errors.New("unterminated string")
+}
+
+func (e *quirkEntry) setToml(key string, value any) error {
+	var target any
+	switch key {
+	case "title":
+		target = &e.Title
+	case "global_checksum":
+		target = &e.GlobalChecksum
+	case "mapper":
+		target = &e.Mapper
+	case "model":
+		target = &e.Model
+	case "rom_size_code":
+		target = &e.RomSizeCode
+	case "ram_size_code":
+		target = &e.RamSizeCode
+	case "note":
+		target = &e.Note
+	case "battery":
+		target = &e.Battery
+	case "rtc":
+		target = &e.Rtc
+	case "palette":
+		target = &e.Palette
+	default:
+		return fmt.Errorf("unknown key %s", key)
+	}
+
+	switch t := target.(type) {
+	case *string:
+		switch v := value.(type) {
+		case string:
+			*t = v
+			return nil
+		case tomlInt:
+			*t = string(v)
+			return nil
+		}
+	case **bool:
+		if v, ok := value.(bool); ok {
+			*t = &v
+			return nil
+		}
+	case *[]string:
+		if v, ok := value.([]string); ok {
+			*t = v
+			return nil
+		}
+	}
+	return fmt.Errorf("%s has the wrong type", key)
+}
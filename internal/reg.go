@@ -23,6 +23,18 @@ const (
 	REG_A
 )
 
+// as written in assembly
+var reg8Name = map[Reg8]string{
+	REG_B:      "B",
+	REG_C:      "C",
+	REG_D:      "D",
+	REG_E:      "E",
+	REG_H:      "H",
+	REG_L:      "L",
+	REG_MEM_HL: "[HL]",
+	REG_A:      "A",
+}
+
 var reg16Name = map[Reg16]string{
 	REG_AF: "Register AF",
 	REG_BC: "Register BC",
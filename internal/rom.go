@@ -1,13 +1,190 @@
 package internal
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
+type CartridgeType uint8
+
+const (
+	CART_ROM_ONLY                CartridgeType = 0x00
+	CART_MBC1                    CartridgeType = 0x01
+	CART_MBC1_RAM                CartridgeType = 0x02
+	CART_MBC1_RAM_BATTERY        CartridgeType = 0x03
+	CART_MBC2                    CartridgeType = 0x05
+	CART_MBC2_BATTERY            CartridgeType = 0x06
+	CART_ROM_RAM                 CartridgeType = 0x08
+	CART_ROM_RAM_BATTERY         CartridgeType = 0x09
+	CART_MMM01                   CartridgeType = 0x0B
+	CART_MMM01_RAM               CartridgeType = 0x0C
+	CART_MMM01_RAM_BATTERY       CartridgeType = 0x0D
+	CART_MBC3_TIMER_BATTERY      CartridgeType = 0x0F
+	CART_MBC3_TIMER_RAM_BATTERY  CartridgeType = 0x10
+	CART_MBC3                    CartridgeType = 0x11
+	CART_MBC3_RAM                CartridgeType = 0x12
+	CART_MBC3_RAM_BATTERY        CartridgeType = 0x13
+	CART_MBC5                    CartridgeType = 0x19
+	CART_MBC5_RAM                CartridgeType = 0x1A
+	CART_MBC5_RAM_BATTERY        CartridgeType = 0x1B
+	CART_MBC5_RUMBLE             CartridgeType = 0x1C
+	CART_MBC5_RUMBLE_RAM         CartridgeType = 0x1D
+	CART_MBC5_RUMBLE_RAM_BATTERY CartridgeType = 0x1E
+	CART_MBC6                    CartridgeType = 0x20
+	CART_MBC7_SENSOR_RUMBLE      CartridgeType = 0x22
+	CART_POCKET_CAMERA           CartridgeType = 0xFC
+	CART_BANDAI_TAMA5            CartridgeType = 0xFD
+	CART_HUC3                    CartridgeType = 0xFE
+	CART_HUC1_RAM_BATTERY        CartridgeType = 0xFF
+)
+
+var cartridgeTypeName = map[CartridgeType]string{
+	CART_ROM_ONLY:                "ROM ONLY",
+	CART_MBC1:                    "MBC1",
+	CART_MBC1_RAM:                "MBC1+RAM",
+	CART_MBC1_RAM_BATTERY:        "MBC1+RAM+BATTERY",
+	CART_MBC2:                    "MBC2",
+	CART_MBC2_BATTERY:            "MBC2+BATTERY",
+	CART_ROM_RAM:                 "ROM+RAM",
+	CART_ROM_RAM_BATTERY:         "ROM+RAM+BATTERY",
+	CART_MMM01:                   "MMM01",
+	CART_MMM01_RAM:               "MMM01+RAM",
+	CART_MMM01_RAM_BATTERY:       "MMM01+RAM+BATTERY",
+	CART_MBC3_TIMER_BATTERY:      "MBC3+TIMER+BATTERY",
+	CART_MBC3_TIMER_RAM_BATTERY:  "MBC3+TIMER+RAM+BATTERY",
+	CART_MBC3:                    "MBC3",
+	CART_MBC3_RAM:                "MBC3+RAM",
+	CART_MBC3_RAM_BATTERY:        "MBC3+RAM+BATTERY",
+	CART_MBC5:                    "MBC5",
+	CART_MBC5_RAM:                "MBC5+RAM",
+	CART_MBC5_RAM_BATTERY:        "MBC5+RAM+BATTERY",
+	CART_MBC5_RUMBLE:             "MBC5+RUMBLE",
+	CART_MBC5_RUMBLE_RAM:         "MBC5+RUMBLE+RAM",
+	CART_MBC5_RUMBLE_RAM_BATTERY: "MBC5+RUMBLE+RAM+BATTERY",
+	CART_MBC6:                    "MBC6",
+	CART_MBC7_SENSOR_RUMBLE:      "MBC7+SENSOR+RUMBLE+RAM+BATTERY",
+	CART_POCKET_CAMERA:           "POCKET CAMERA",
+	CART_BANDAI_TAMA5:            "BANDAI TAMA5",
+	CART_HUC3:                    "HuC3",
+	CART_HUC1_RAM_BATTERY:        "HuC1+RAM+BATTERY",
+}
+
+func (c CartridgeType) String() string {
+	if name, ok := cartridgeTypeName[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("UNKNOWN (0x%02x)", uint8(c))
+}
+
+func (c CartridgeType) HasRam() bool {
+	switch c {
+	case CART_MBC1_RAM, CART_MBC1_RAM_BATTERY,
+		CART_ROM_RAM, CART_ROM_RAM_BATTERY,
+		CART_MMM01_RAM, CART_MMM01_RAM_BATTERY,
+		CART_MBC3_TIMER_RAM_BATTERY, CART_MBC3_RAM, CART_MBC3_RAM_BATTERY,
+		CART_MBC5_RAM, CART_MBC5_RAM_BATTERY, CART_MBC5_RUMBLE_RAM, CART_MBC5_RUMBLE_RAM_BATTERY,
+		CART_POCKET_CAMERA, CART_HUC3, CART_HUC1_RAM_BATTERY:
+		return true
+	}
+	return false
+}
+
+func (c CartridgeType) HasRtc() bool {
+	return c == CART_MBC3_TIMER_BATTERY || c == CART_MBC3_TIMER_RAM_BATTERY
+}
+
+func (c CartridgeType) HasRumble() bool {
+	switch c {
+	case CART_MBC5_RUMBLE, CART_MBC5_RUMBLE_RAM, CART_MBC5_RUMBLE_RAM_BATTERY:
+		return true
+	}
+	return false
+}
+
+func (c CartridgeType) HasBattery() bool {
+	switch c {
+	case CART_MBC1_RAM_BATTERY, CART_MBC2_BATTERY, CART_ROM_RAM_BATTERY,
+		CART_MMM01_RAM_BATTERY, CART_MBC3_TIMER_BATTERY, CART_MBC3_TIMER_RAM_BATTERY,
+		CART_MBC3_RAM_BATTERY, CART_MBC5_RAM_BATTERY, CART_MBC5_RUMBLE_RAM_BATTERY,
+		CART_MBC7_SENSOR_RUMBLE, CART_POCKET_CAMERA, CART_HUC3, CART_HUC1_RAM_BATTERY:
+		return true
+	}
+	return false
+}
+
+// Cartridge header, located at 0x0100-0x014F
+// https://gbdev.io/pandocs/The_Cartridge_Header.html
+const (
+	HEADER_LOGO             = 0x0104
+	HEADER_TITLE            = 0x0134
+	HEADER_MANUFACTURER     = 0x013F
+	HEADER_CGB_FLAG         = 0x0143
+	HEADER_NEW_LICENSEE     = 0x0144
+	HEADER_SGB_FLAG         = 0x0146
+	HEADER_CARTRIDGE_TYPE   = 0x0147
+	HEADER_ROM_SIZE         = 0x0148
+	HEADER_RAM_SIZE         = 0x0149
+	HEADER_DESTINATION      = 0x014A
+	HEADER_OLD_LICENSEE     = 0x014B
+	HEADER_VERSION          = 0x014C
+	HEADER_CHECKSUM         = 0x014D
+	HEADER_GLOBAL_CHECKSUM  = 0x014E
+	HEADER_END              = 0x0150
+	ROM_BANK_SIZE           = 0x4000
+	RAM_BANK_SIZE           = 0x2000
+	USE_NEW_LICENSEE_MARKER = 0x33
+)
+
+var (
+	ErrRomTooShort    = errors.New("rom is too short to contain a cartridge header")
+	ErrRomTruncated   = errors.New("rom is smaller than the size declared in its header")
+	ErrRomSizeCode    = errors.New("invalid rom size code in cartridge header")
+	ErrRamSizeCode    = errors.New("invalid ram size code in cartridge header")
+	ErrHeaderChecksum = errors.New("cartridge header checksum mismatch")
+	ErrRomPathIsDir   = errors.New("rom path is a directory")
+	ErrRomEmpty       = errors.New("rom file is empty")
+)
+
+type Header struct {
+	Title            string
+	ManufacturerCode string
+	CgbFlag          uint8
+	SgbFlag          uint8
+	CartridgeType    CartridgeType
+	RomSizeCode      uint8
+	RamSizeCode      uint8
+	DestinationCode  uint8
+	OldLicenseeCode  uint8
+	NewLicenseeCode  string
+	Version          uint8
+	HeaderChecksum   uint8
+	GlobalChecksum   uint16
+
+	// The boot ROM refuses to start a cartridge whose header checksum is wrong,
+	// the global checksum is never verified by hardware, so a mismatch is only noted.
+	HeaderChecksumValid bool
+	GlobalChecksumValid bool
+}
+
 type Rom struct {
-	data []byte
+	data   []byte
+	Header Header
+	Path   string // file the rom was loaded from, empty for roms created from memory
+	Entry  string // name of the rom inside the archive at Path, empty for plain rom files
+	Patch  string // patch file applied to the rom, see ApplyPatchFile
+}
+
+// SavePath returns where the battery save of the rom goes.
+// Roms from archives are saved next to the archive under their own name,
+// so an archive holding several games gets one save per game.
+func (r *Rom) SavePath() string {
+	if r.Entry != "" {
+		return filepath.Join(filepath.Dir(r.Path), SavePath(filepath.Base(filepath.FromSlash(r.Entry))))
+	}
+	return SavePath(r.Path)
 }
 
 func (r *Rom) GetData() []byte {
@@ -18,25 +195,220 @@ func (r *Rom) GetLength() int {
 	return len(r.data)
 }
 
-func NewRom(path string) *Rom {
-	file, err := os.Open(path)
+func NewRom(path string) (*Rom, error) {
+	return NewRomFromArchive(path, "")
+}
+
+// NewRomFromArchive loads a rom that may be packed in a .zip, .gz or .tar.gz archive.
+// entry names the file to load from the archive, if empty the first .gb, .gbc or .sgb file is used.
+// Plain rom files are loaded as they are.
+func NewRomFromArchive(path string, entry string) (*Rom, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening rom %q: %w", path, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("opening rom %q: %w", path, ErrRomPathIsDir)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading rom %q: %w", path, err)
+	}
+
+	data, name, err := unpackRom(data, path, entry)
+	if err != nil {
+		return nil, fmt.Errorf("unpacking rom %q: %w", path, err)
+	}
+
+	rom, err := NewRomFromBytes(data)
 	if err != nil {
-		fmt.Println("Error opening file:", err)
-		return nil
+		if name != "" {
+			return nil, fmt.Errorf("loading rom %q from %q: %w", name, path, err)
+		}
+		return nil, fmt.Errorf("loading rom %q: %w", path, err)
+	}
+	rom.Path = path
+	rom.Entry = name
+	return rom, nil
+}
+
+// NewRomFromBytes parses and validates the cartridge header of data.
+// The slice is kept by the Rom and must not be modified afterwards.
+func NewRomFromBytes(data []byte) (*Rom, error) {
+	if len(data) == 0 {
+		return nil, ErrRomEmpty
+	}
+	if len(data) < HEADER_END {
+		return nil, fmt.Errorf("%w: %d bytes", ErrRomTooShort, len(data))
+	}
+
+	rom := &Rom{data: data}
+	rom.Header = parseHeader(data)
+
+	// a quirk entry vouches for a dump with a broken header and can fix its size codes
+	q, known := QUIRKS[QuirkKey{rom.Header.Title, rom.Header.GlobalChecksum}]
+	if q.RomSizeCode != nil {
+		rom.Header.RomSizeCode = *q.RomSizeCode
+	}
+	if q.RamSizeCode != nil {
+		rom.Header.RamSizeCode = *q.RamSizeCode
+	}
+
+	if err := rom.Header.validate(data, known); err != nil {
+		return nil, err
+	}
+	return rom, nil
+}
+
+func parseHeader(data []byte) Header {
+	h := Header{
+		CgbFlag:         data[HEADER_CGB_FLAG],
+		SgbFlag:         data[HEADER_SGB_FLAG],
+		CartridgeType:   CartridgeType(data[HEADER_CARTRIDGE_TYPE]),
+		RomSizeCode:     data[HEADER_ROM_SIZE],
+		RamSizeCode:     data[HEADER_RAM_SIZE],
+		DestinationCode: data[HEADER_DESTINATION],
+		OldLicenseeCode: data[HEADER_OLD_LICENSEE],
+		Version:         data[HEADER_VERSION],
+		HeaderChecksum:  data[HEADER_CHECKSUM],
+		GlobalChecksum:  uint16(data[HEADER_GLOBAL_CHECKSUM])<<8 | uint16(data[HEADER_GLOBAL_CHECKSUM+1]),
+	}
+
+	// On CGB cartridges the last bytes of the title area are reused for the
+	// manufacturer code and the CGB flag, so the title gets shorter.
+	titleEnd := HEADER_CGB_FLAG + 1
+	if h.IsCgb() {
+		titleEnd = HEADER_CGB_FLAG
+		manufacturer := data[HEADER_MANUFACTURER:HEADER_CGB_FLAG]
+		if isUpperAlnum(manufacturer) {
+			h.ManufacturerCode = string(manufacturer)
+			titleEnd = HEADER_MANUFACTURER
+		}
+	}
+	h.Title = headerString(data[HEADER_TITLE:titleEnd])
+
+	if h.OldLicenseeCode == USE_NEW_LICENSEE_MARKER {
+		h.NewLicenseeCode = headerString(data[HEADER_NEW_LICENSEE : HEADER_NEW_LICENSEE+2])
+	}
+
+	h.HeaderChecksumValid = computeHeaderChecksum(data) == h.HeaderChecksum
+	h.GlobalChecksumValid = computeGlobalChecksum(data) == h.GlobalChecksum
+	return h
+}
+
+// validate rejects broken headers. A quirked dump is known to have a broken header,
+// it only has to be as large as its (possibly overridden) size code says.
+func (h *Header) validate(data []byte, quirked bool) error {
+	romSize, ok := h.RomSize()
+	if !ok && !quirked {
+		return fmt.Errorf("%w: 0x%02x", ErrRomSizeCode, h.RomSizeCode)
+	}
+	if _, ok := h.RamSize(); !ok && !quirked {
+		return fmt.Errorf("%w: 0x%02x", ErrRamSizeCode, h.RamSizeCode)
+	}
+	if !h.HeaderChecksumValid && !quirked {
+		return fmt.Errorf("%w: header says 0x%02x, computed 0x%02x", ErrHeaderChecksum, h.HeaderChecksum, computeHeaderChecksum(data))
+	}
+	if len(data) < romSize {
+		return fmt.Errorf("%w: header declares %d bytes, file has %d", ErrRomTruncated, romSize, len(data))
+	}
+	return nil
+}
+
+// IsCgb reports whether the cartridge supports (0x80) or requires (0xC0) CGB functions
+func (h *Header) IsCgb() bool {
+	return GetBit(h.CgbFlag, 7)
+}
+
+func (h *Header) IsCgbOnly() bool {
+	return h.CgbFlag == 0xC0
+}
+
+// SGB functions are only enabled when the flag is 0x03 and the old licensee code is 0x33
+func (h *Header) IsSgb() bool {
+	return h.SgbFlag == 0x03 && h.OldLicenseeCode == USE_NEW_LICENSEE_MARKER
+}
+
+func (h *Header) IsJapanese() bool {
+	return h.DestinationCode == 0x00
+}
+
+// RomSize returns the ROM size in bytes declared by the header
+func (h *Header) RomSize() (int, bool) {
+	if h.RomSizeCode > 0x08 {
+		return 0, false
+	}
+	return 0x8000 << h.RomSizeCode, true
+}
+
+func (h *Header) RomBanks() int {
+	size, _ := h.RomSize()
+	return size / ROM_BANK_SIZE
+}
+
+// RamSize returns the external RAM size in bytes declared by the header
+func (h *Header) RamSize() (int, bool) {
+	switch h.RamSizeCode {
+	case 0x00:
+		return 0, true
+	case 0x01:
+		return 0x800, true // unofficial, only used by some homebrew
+	case 0x02:
+		return 0x2000, true
+	case 0x03:
+		return 0x8000, true
+	case 0x04:
+		return 0x20000, true
+	case 0x05:
+		return 0x10000, true
+	}
+	return 0, false
+}
+
+func (h *Header) String() string {
+	return fmt.Sprintf("%q (%s, rom code 0x%02x, ram code 0x%02x, v%d)", h.Title, h.CartridgeType, h.RomSizeCode, h.RamSizeCode, h.Version)
+}
+
+// x = 0: for i = 0x0134 to 0x014C: x = x - rom[i] - 1
+func computeHeaderChecksum(data []byte) uint8 {
+	var checksum uint8
+	for _, b := range data[HEADER_TITLE:HEADER_CHECKSUM] {
+		checksum = checksum - b - 1
 	}
-	defer file.Close()
+	return checksum
+}
 
-	reader := bufio.NewReader(file)
+// sum of all bytes of the rom except the two checksum bytes themselves
+func computeGlobalChecksum(data []byte) uint16 {
+	var checksum uint16
+	for i, b := range data {
+		if i == HEADER_GLOBAL_CHECKSUM || i == HEADER_GLOBAL_CHECKSUM+1 {
+			continue
+		}
+		checksum += uint16(b)
+	}
+	return checksum
+}
 
-	rom := &Rom{}
-	for {
-		b, err := reader.ReadByte()
-		if err != nil {
+func headerString(raw []byte) string {
+	end := len(raw)
+	for i, b := range raw {
+		if b == 0 {
+			end = i
 			break
 		}
-		rom.data = append(rom.data, b)
 	}
-	return rom
+	return strings.TrimRight(string(raw[:end]), " ")
+}
+
+func isUpperAlnum(raw []byte) bool {
+	for _, b := range raw {
+		if !(b >= 'A' && b <= 'Z' || b >= '0' && b <= '9') {
+			return false
+		}
+	}
+	return true
 }
 
 // returns data and instructions to increment PC by
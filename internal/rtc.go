@@ -0,0 +1,250 @@
+package internal
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// MBC3 real-time clock registers, selected by writing 0x08-0x0C to 0x4000-0x5FFF
+const (
+	RTC_S  uint8 = 0x08 // Seconds 0-59
+	RTC_M  uint8 = 0x09 // Minutes 0-59
+	RTC_H  uint8 = 0x0A // Hours 0-23
+	RTC_DL uint8 = 0x0B // Lower 8 bits of day counter
+	RTC_DH uint8 = 0x0C // Bit 0: day counter bit 8, bit 6: halt, bit 7: day counter carry
+)
+
+// The RTC runs off its own 32768 Hz crystal, one second is 2^20 M-cycles at normal speed
+const RTC_MCYCLES_PER_SECOND = 1 << 20
+
+// Save footer appended to the cartridge RAM in .sav files, as written by BGB and VBA-M:
+// the live and the latched RTC_S-RTC_DH registers as 32 bit little endian values,
+// followed by the unix timestamp of the save. Older saves use a 32 bit timestamp.
+const (
+	RTC_FOOTER_SIZE        = 48
+	RTC_FOOTER_SIZE_LEGACY = 44
+)
+
+var ErrRtcFooterSize = errors.New("invalid rtc save footer size")
+
+// https://gbdev.io/pandocs/MBC3.html#the-clock-counter-registers
+type Rtc struct {
+	Seconds  uint8
+	Minutes  uint8
+	Hours    uint8
+	Days     uint16 // 9 bit day counter
+	Halt     bool
+	DayCarry bool
+
+	latched   [5]uint8 // values returned for RTC_S-RTC_DH after latching
+	lastLatch uint8    // last value written to 0x6000-0x7FFF, 0x00 then 0x01 latches
+
+	mCycles uint64 // sub-second counter
+
+	// Advance from the host wall clock instead of emulated cycles,
+	// so the time keeps up with reality even when the emulator is paused or fast forwarded
+	UseHostClock bool
+	lastHostSync time.Time
+}
+
+func NewRtc() *Rtc {
+	rtc := &Rtc{}
+	rtc.lastLatch = 0xFF
+	rtc.lastHostSync = time.Now()
+	rtc.Latch()
+	return rtc
+}
+
+func (r *Rtc) Tick(mCycles uint64) {
+	if r.UseHostClock || r.Halt {
+		return
+	}
+	r.mCycles += mCycles
+	if r.mCycles >= RTC_MCYCLES_PER_SECOND {
+		r.AdvanceSeconds(r.mCycles / RTC_MCYCLES_PER_SECOND)
+		r.mCycles %= RTC_MCYCLES_PER_SECOND
+	}
+}
+
+// syncHost catches the clock up with the host time, the game can only
+// observe the clock through latches and register accesses so this is done lazily
+func (r *Rtc) syncHost() {
+	now := time.Now()
+	if !r.UseHostClock {
+		r.lastHostSync = now
+		return
+	}
+	elapsed := now.Sub(r.lastHostSync)
+	seconds := uint64(elapsed / time.Second)
+	if seconds == 0 {
+		return
+	}
+	r.lastHostSync = r.lastHostSync.Add(time.Duration(seconds) * time.Second)
+	if !r.Halt {
+		r.AdvanceSeconds(seconds)
+	}
+}
+
+// AdvanceSeconds moves the clock forward, counting like the hardware does:
+// out of range values written by the game keep counting up to the register width
+// before wrapping to 0, without carrying into the next register.
+func (r *Rtc) AdvanceSeconds(seconds uint64) {
+	const secondsPerDay = 24 * 60 * 60
+	for seconds > 0 {
+		// with a valid time of day a whole day can be skipped at once
+		if seconds >= secondsPerDay && r.Seconds < 60 && r.Minutes < 60 && r.Hours < 24 {
+			r.incrementDays()
+			seconds -= secondsPerDay
+			continue
+		}
+		r.incrementSeconds()
+		seconds--
+	}
+}
+
+func (r *Rtc) incrementSeconds() {
+	r.Seconds = (r.Seconds + 1) & 0x3F
+	if r.Seconds != 60 {
+		return
+	}
+	r.Seconds = 0
+
+	r.Minutes = (r.Minutes + 1) & 0x3F
+	if r.Minutes != 60 {
+		return
+	}
+	r.Minutes = 0
+
+	r.Hours = (r.Hours + 1) & 0x1F
+	if r.Hours != 24 {
+		return
+	}
+	r.Hours = 0
+
+	r.incrementDays()
+}
+
+func (r *Rtc) incrementDays() {
+	r.Days++
+	if r.Days > 0x1FF {
+		r.Days = 0
+		r.DayCarry = true
+	}
+}
+
+func (r *Rtc) Latch() {
+	r.syncHost()
+	r.latched[0] = r.Seconds
+	r.latched[1] = r.Minutes
+	r.latched[2] = r.Hours
+	r.latched[3] = uint8(r.Days)
+	r.latched[4] = r.getDH()
+}
+
+// WriteLatch handles writes to 0x6000-0x7FFF
+func (r *Rtc) WriteLatch(value uint8) {
+	if r.lastLatch == 0x00 && value == 0x01 {
+		r.Latch()
+	}
+	r.lastLatch = value
+}
+
+func (r *Rtc) getDH() uint8 {
+	var dh uint8 = 0x3E // unused bits read as 1
+	SetBit(&dh, 0, r.Days > 0xFF)
+	SetBit(&dh, 6, r.Halt)
+	SetBit(&dh, 7, r.DayCarry)
+	return dh
+}
+
+func (r *Rtc) Read(reg uint8) uint8 {
+	switch reg {
+	case RTC_S:
+		return r.latched[0] | 0xC0
+	case RTC_M:
+		return r.latched[1] | 0xC0
+	case RTC_H:
+		return r.latched[2] | 0xE0
+	case RTC_DL:
+		return r.latched[3]
+	case RTC_DH:
+		return r.latched[4]
+	}
+	return 0xFF
+}
+
+func (r *Rtc) Write(reg uint8, value uint8) {
+	r.syncHost()
+	switch reg {
+	case RTC_S:
+		r.Seconds = value & 0x3F
+		r.mCycles = 0 // writing the seconds resets the sub-second divider
+	case RTC_M:
+		r.Minutes = value & 0x3F
+	case RTC_H:
+		r.Hours = value & 0x1F
+	case RTC_DL:
+		r.Days = r.Days&0x100 | uint16(value)
+	case RTC_DH:
+		r.Days = r.Days&0xFF | uint16(value&0x01)<<8
+		r.Halt = GetBit(value, 6)
+		r.DayCarry = GetBit(value, 7)
+	}
+	// writes are visible without having to latch again
+	r.latched[reg-RTC_S] = r.readLive(reg)
+}
+
+func (r *Rtc) readLive(reg uint8) uint8 {
+	switch reg {
+	case RTC_S:
+		return r.Seconds
+	case RTC_M:
+		return r.Minutes
+	case RTC_H:
+		return r.Hours
+	case RTC_DL:
+		return uint8(r.Days)
+	}
+	return r.getDH()
+}
+
+func (r *Rtc) MarshalFooter(now time.Time) []byte {
+	r.syncHost()
+	footer := make([]byte, RTC_FOOTER_SIZE)
+	for reg := RTC_S; reg <= RTC_DH; reg++ {
+		i := int(reg - RTC_S)
+		binary.LittleEndian.PutUint32(footer[i*4:], uint32(r.readLive(reg)))
+		binary.LittleEndian.PutUint32(footer[20+i*4:], uint32(r.latched[i]))
+	}
+	binary.LittleEndian.PutUint64(footer[40:], uint64(now.Unix()))
+	return footer
+}
+
+// UnmarshalFooter restores the clock from a save footer and advances it
+// by the real time that passed since the save was written.
+func (r *Rtc) UnmarshalFooter(footer []byte, now time.Time) error {
+	var savedAt int64
+	switch len(footer) {
+	case RTC_FOOTER_SIZE:
+		savedAt = int64(binary.LittleEndian.Uint64(footer[40:]))
+	case RTC_FOOTER_SIZE_LEGACY:
+		savedAt = int64(binary.LittleEndian.Uint32(footer[40:]))
+	default:
+		return ErrRtcFooterSize
+	}
+
+	for reg := RTC_S; reg <= RTC_DH; reg++ {
+		i := int(reg - RTC_S)
+		r.Write(reg, uint8(binary.LittleEndian.Uint32(footer[i*4:])))
+	}
+	for i := range r.latched {
+		r.latched[i] = uint8(binary.LittleEndian.Uint32(footer[20+i*4:]))
+	}
+
+	if elapsed := now.Unix() - savedAt; elapsed > 0 && !r.Halt {
+		r.AdvanceSeconds(uint64(elapsed))
+	}
+	r.lastHostSync = now
+	return nil
+}
@@ -0,0 +1,73 @@
+package internal
+
+import "bytes"
+
+var nintendoLogo = []byte{
+	0xCE, 0xED, 0x66, 0x66, 0xCC, 0x0D, 0x00, 0x0B, 0x03, 0x73, 0x00, 0x83, 0x00, 0x0C, 0x00, 0x0D,
+	0x00, 0x08, 0x11, 0x1F, 0x88, 0x89, 0x00, 0x0E, 0xDC, 0xCC, 0x6E, 0xE6, 0xDD, 0xDD, 0xD9, 0x99,
+	0xBB, 0xBB, 0x67, 0x63, 0x6E, 0x0E, 0xEC, 0xCC, 0xDD, 0xDC, 0x99, 0x9F, 0xBB, 0xB9, 0x33, 0x3E,
+}
+
+// Unlicensed Sachen MMC1 boards, used for single games and for the 4-in-1/6-in-1 style multicarts.
+// The menu picks a game with an outer bank: the rom bank bits set in mask come from baseBank,
+// the rest from the inner bank the game selects like on an MBC1. Bank 0x0000-0x3FFF shows the
+// first bank of the game. Base and mask can only be written while the inner bank has bits 4 and 5 set,
+// once the menu has started a game it can not leave its slot. There is no cartridge RAM.
+//
+// The header holds Sachen's own logo, the Nintendo logo sits at 0x0184. While the boot rom runs
+// the cart answers reads of 0x0100-0x01FF from 0x0180-0x01FF so the logo check passes.
+type SachenMmc1 struct {
+	mbcBase
+
+	baseBank  uint8 // 0x0000-0x1FFF, menu only
+	innerBank uint8 // 0x2000-0x3FFF, 0 reads as 1
+	mask      uint8 // 0x4000-0x5FFF, menu only
+}
+
+// Sachen dumps have a different logo in the header and the Nintendo logo 0x80 bytes further
+func isSachenMmc1(data []byte) bool {
+	if len(data) < 0x200 {
+		return false
+	}
+	logoSize := len(nintendoLogo)
+	return !bytes.Equal(data[HEADER_LOGO:HEADER_LOGO+logoSize], nintendoLogo) &&
+		bytes.Equal(data[HEADER_LOGO|0x80:(HEADER_LOGO|0x80)+logoSize], nintendoLogo)
+}
+
+func NewSachenMmc1(rom *Rom) *SachenMmc1 {
+	m := &SachenMmc1{mbcBase: newMbcBase(rom), innerBank: 1}
+	m.updateBanks()
+	return m
+}
+
+func (m *SachenMmc1) menuUnlocked() bool {
+	return m.innerBank&0x30 == 0x30
+}
+
+func (m *SachenMmc1) WriteRom(address uint16, value uint8) {
+	switch address >> 13 {
+	case 0:
+		if m.menuUnlocked() {
+			m.baseBank = value
+		}
+	case 1:
+		m.innerBank = max(value, 1)
+	case 2:
+		if m.menuUnlocked() {
+			m.mask = value
+		}
+	}
+	m.updateBanks()
+}
+
+func (m *SachenMmc1) updateBanks() {
+	m.romBank0 = int(m.baseBank & m.mask)
+	m.romBankN = int(m.innerBank&^m.mask | m.baseBank&m.mask)
+}
+
+func (m *SachenMmc1) readRomDuringBoot(address uint16) uint8 {
+	if address&0xFF00 == 0x0100 {
+		address |= 0x80
+	}
+	return m.ReadRom(address)
+}
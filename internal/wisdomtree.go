@@ -0,0 +1,22 @@
+package internal
+
+// Unlicensed Wisdom Tree boards switch 32 KiB banks covering all of 0x0000-0x7FFF.
+// The bank is taken from the lower address byte of a write to 0x0000-0x3FFF, the value is ignored.
+type WisdomTree struct {
+	mbcBase
+}
+
+func NewWisdomTree(rom *Rom) *WisdomTree {
+	m := &WisdomTree{mbcBase: newMbcBase(rom)}
+	m.ramEnabled = true
+	return m
+}
+
+func (m *WisdomTree) WriteRom(address uint16, value uint8) {
+	if address >= 0x4000 {
+		return
+	}
+	bank := int(address & 0xFF)
+	m.romBank0 = bank * 2
+	m.romBankN = bank*2 + 1
+}
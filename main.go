@@ -1,8 +1,12 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"go-boy/debugger"
+	"go-boy/disasm"
 	"go-boy/emulator"
+	"go-boy/internal"
 	"os"
 	"os/signal"
 	"runtime"
@@ -37,31 +41,59 @@ func main() {
 
 	runtime.LockOSThread()
 
-	isDebugMode := false
-	test := false
-	profile := false
-	argsWithoutProg := os.Args[1:]
+	if len(os.Args) > 1 && os.Args[1] == "disasm" {
+		os.Exit(runDisasm(os.Args[2:]))
+	}
+
+	isDebugMode := flag.Bool("debug", false, "start with the visual debugger")
+	test := flag.Bool("test", false, "run the test roms listed in main.go")
+	logEnabled := flag.Bool("log", false, "log cpu state in Gameboy-Doctor format to ./gb-log")
+	profile := flag.Bool("profile", false, "write a cpu profile to ./cpu.prof")
+	scale := flag.Int("scale", emulator.ScreenSizeMultiplier, "window size as a multiple of 160x144")
+	entry := flag.String("entry", "", "file to load from a rom archive, the first rom by default")
+	patchPath := flag.String("patch", "", "ips, ups or bps patch to apply, by default one next to the rom is used")
+	saveDir := flag.String("savedir", "", "directory for .sav files, by default they are stored next to the rom")
+	noSave := flag.Bool("nosave", false, "do not load or write .sav files")
+	mute := flag.Bool("mute", false, "start without sound (there is no audio output yet, this only silences HuC3 tones)")
+	rtcHostClock := flag.Bool("rtc-host-clock", false, "run cartridge clocks on the host time instead of emulated cycles")
+	model := flag.String("model", "auto", "hardware model: auto, dmg0, dmg, mgb, sgb, sgb2, cgb or agb")
+	bootRom := flag.String("bootrom", "", "dmg, mgb or cgb boot rom to run before the game")
+	cameraImage := flag.String("camera", "", "png shown to the Pocket Camera, a test pattern by default")
+	quirks := flag.String("quirks", "", "json or toml file with game quirks that extends the built-in database")
+	symPath := flag.String("sym", "", "RGBDS .sym file with label names for the debugger")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [rom]\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s disasm [flags] rom\n\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "The rom can be a .gb/.gbc/.sgb file or a .zip, .gz or .tar.gz archive.")
+		fmt.Fprintln(flag.CommandLine.Output(), "Without a rom, drop one on the window to start it.")
+		fmt.Fprintln(flag.CommandLine.Output())
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *scale < 1 {
+		fmt.Fprintln(os.Stderr, "scale has to be at least 1")
+		os.Exit(2)
+	}
+	emulator.ScreenSizeMultiplier = *scale
+
 	var logFile *os.File
-	for _, arg := range argsWithoutProg {
-		switch arg {
-		case "--debug":
-			isDebugMode = true
-		case "--test":
-			test = true
-		case "--log":
-			filename := "gb-log"
-			os.Remove(filename)
-			var err error
-			logFile, err = os.OpenFile(filename, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
-			if err != nil {
-				panic(err)
-			}
-		case "--profile":
-			profile = true
+	if *logEnabled {
+		filename := "gb-log"
+		os.Remove(filename)
+		var err error
+		logFile, err = os.OpenFile(filename, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
+		if err != nil {
+			panic(err)
 		}
 	}
 
-	if profile {
+	if *profile {
 		f, err := os.Create("cpu.prof")
 		if err != nil {
 			panic(err)
@@ -88,15 +120,60 @@ func main() {
 	}
 
 	var e *Emulator = emulator.NewEmulator()
+	var err error
+	e.PatchPath = *patchPath
+	e.SaveDir = *saveDir
+	e.DisableSaves = *noSave
+	e.Mute = *mute
+	e.RtcUseHostClock = *rtcHostClock
+	if e.Model, err = internal.ParseModel(*model); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *bootRom != "" {
+		data, err := internal.LoadBootRom(*bootRom)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		e.BootRom = data
+	}
+	if *quirks != "" {
+		if err := internal.LoadQuirks(*quirks); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	if *cameraImage != "" {
+		sensor, err := internal.LoadImageSensor(*cameraImage)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		e.CameraSensor = sensor
+	}
+
+	if flag.NArg() == 1 && !*test {
+		if err := e.OpenRom(flag.Arg(0), *entry); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
 
-	if isDebugMode {
+	if *isDebugMode {
 		dbg := debugger.NewDebugger()
 		dbg.SetEmu(e)
+		if *symPath != "" {
+			if dbg.Symbols, err = disasm.LoadSymbols(*symPath); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}
 		dbg.RunEmulator()
 
 	} else {
 
-		if test {
+		if *test {
 			e.RunTests(tests)
 		}
 		e.Cpu.LogFile = logFile